@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"web-tools-platform/backend/internal/config"
+	"web-tools-platform/backend/internal/models"
+	"web-tools-platform/backend/internal/services"
+)
+
+var apiKeyUsage = `usage: server apikey <command>
+
+commands:
+  create <name> <scope>...   issue a key with the given scopes (` + strings.Join(models.Scopes, ", ") + `)
+  list                       list issued keys
+  revoke <id>                revoke a key`
+
+// runAPIKey implements the "apikey" subcommand, used to issue the first admin
+// key before any can be issued through the API
+func runAPIKey(dbConfig config.Database, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing apikey command\n%s", apiKeyUsage)
+	}
+
+	db, err := openDatabase(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	service := services.NewService(db)
+
+	switch args[0] {
+	case "create":
+		if len(args) < 3 {
+			return fmt.Errorf("create needs a name and at least one scope\n%s", apiKeyUsage)
+		}
+		key, err := service.CreateAPIKey(ctx, models.APIKeyRequest{Name: args[1], Scopes: args[2:]})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created API key %s (%s)\n", key.ID, strings.Join(key.Scopes, ", "))
+		fmt.Printf("Key: %s\n", key.Key)
+		fmt.Println("Store the key now, it cannot be shown again.")
+	case "list":
+		keys, err := service.GetAPIKeys(ctx)
+		if err != nil {
+			return err
+		}
+		printAPIKeys(keys)
+	case "revoke":
+		if len(args) != 2 {
+			return fmt.Errorf("revoke needs a key ID\n%s", apiKeyUsage)
+		}
+		if err := service.RevokeAPIKey(ctx, args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Revoked API key %s\n", args[1])
+	default:
+		return fmt.Errorf("unknown apikey command: %s\n%s", args[0], apiKeyUsage)
+	}
+
+	return nil
+}
+
+// printAPIKeys writes a table of API keys to stdout
+func printAPIKeys(keys []models.APIKey) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tPREFIX\tSCOPES\tSTATUS\tLAST USED")
+	for _, key := range keys {
+		status, lastUsed := "active", "-"
+		if key.RevokedAt != nil {
+			status = "revoked"
+		}
+		if key.LastUsedAt != nil {
+			lastUsed = key.LastUsedAt.UTC().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			key.ID, key.Name, key.Prefix, strings.Join(key.Scopes, ","), status, lastUsed)
+	}
+	w.Flush()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"web-tools-platform/backend/internal/auth"
+	"web-tools-platform/backend/internal/config"
+	"web-tools-platform/backend/internal/database"
+	"web-tools-platform/backend/internal/middleware"
+	"web-tools-platform/backend/internal/models"
+	"web-tools-platform/backend/internal/services"
+)
+
+// setupAuth builds the authentication middleware. Anonymous callers may read
+// and process tools unless authentication is required, in which case every
+// API request needs a login session or an API key. Changing or deleting
+// shared recipes and schemas always needs one. Failed authentications are
+// limited per client IP by failureLimit, unless it is zero.
+func setupAuth(db *database.DB, oidc *auth.OIDC, required bool, failureLimit middleware.Limit, failureStore middleware.RateLimitStore) gin.HandlerFunc {
+	authConfig := middleware.AuthConfig{
+		Authenticator:   services.NewService(db),
+		AnonymousScopes: []string{models.ScopeToolsRead, models.ScopeToolsProcess},
+		FailureLimit:    failureLimit,
+		FailureStore:    failureStore,
+	}
+	if oidc != nil {
+		authConfig.Sessions = oidc
+	}
+
+	if required {
+		authConfig.AnonymousScopes = nil
+		logrus.Info("Authentication required")
+	}
+
+	return middleware.Auth(authConfig)
+}
+
+// setupOIDC configures OpenID Connect login. Login is disabled, and nil
+// returned, when no issuer is configured.
+func setupOIDC(oidcConfig config.OIDC) (*auth.OIDC, error) {
+	if oidcConfig.Issuer == "" {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	oidc, err := auth.NewOIDC(ctx, auth.Config{
+		Issuer:        oidcConfig.Issuer,
+		ClientID:      oidcConfig.ClientID,
+		ClientSecret:  oidcConfig.ClientSecret,
+		RedirectURL:   oidcConfig.RedirectURL,
+		Scopes:        oidcConfig.Scopes,
+		SessionSecret: oidcConfig.SessionSecret,
+		SessionTTL:    oidcConfig.SessionTTL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.WithField("issuer", oidcConfig.Issuer).Info("OpenID Connect login enabled")
+	return oidc, nil
+}
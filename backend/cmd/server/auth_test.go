@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"web-tools-platform/backend/internal/config"
+	"web-tools-platform/backend/internal/database"
+	"web-tools-platform/backend/internal/handlers"
+	"web-tools-platform/backend/internal/middleware"
+	"web-tools-platform/backend/internal/models"
+	"web-tools-platform/backend/internal/services"
+)
+
+// authTestServer is the API with authentication, backed by a fresh SQLite
+// database. Anonymous callers get no scopes when authentication is required.
+type authTestServer struct {
+	router  *gin.Engine
+	service *services.Service
+}
+
+func newAuthTestServer(t *testing.T, required bool) *authTestServer {
+	t.Helper()
+	return newLimitedAuthTestServer(t, required, middleware.Limit{})
+}
+
+// newLimitedAuthTestServer is newAuthTestServer with failed authentications
+// limited per client IP by failureLimit
+func newLimitedAuthTestServer(t *testing.T, required bool, failureLimit middleware.Limit) *authTestServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := database.Initialize(database.DriverSQLite, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("opening the database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.SyncTools(context.Background(), services.Catalog()); err != nil {
+		t.Fatalf("syncing tools: %v", err)
+	}
+
+	cfg := config.Default()
+	router, err := setupRouter(cfg, setupAuth(db, nil, required, failureLimit, middleware.NewMemoryStore()))
+	if err != nil {
+		t.Fatalf("setupRouter: %v", err)
+	}
+	setupRoutes(router, cfg, handlers.NewHandler(db, services.DefaultLimits), handlers.NewAuthHandler(nil), noRateLimit)
+
+	return &authTestServer{router: router, service: services.NewService(db)}
+}
+
+// apiKey issues a key with scopes
+func (s *authTestServer) apiKey(t *testing.T, scopes ...string) string {
+	t.Helper()
+	key, err := s.service.CreateAPIKey(context.Background(), models.APIKeyRequest{Name: "test", Scopes: scopes})
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	return key.Key
+}
+
+// do sends a request, with key as bearer token unless it is empty
+func (s *authTestServer) do(method, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	s := newAuthTestServer(t, true)
+
+	readKey := s.apiKey(t, models.ScopeToolsRead)
+	processKey := s.apiKey(t, models.ScopeToolsRead, models.ScopeToolsProcess)
+	adminKey := s.apiKey(t, models.ScopeAdmin)
+
+	revoked, err := s.service.CreateAPIKey(context.Background(), models.APIKeyRequest{Name: "revoked", Scopes: []string{models.ScopeToolsRead}})
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	if err := s.service.RevokeAPIKey(context.Background(), revoked.ID); err != nil {
+		t.Fatalf("RevokeAPIKey: %v", err)
+	}
+
+	process := `{"input":"hello","settings":{"mode":"encode"}}`
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		key    string
+		body   string
+		status int
+	}{
+		{"anonymous read", http.MethodGet, "/api/v1/tools", "", "", http.StatusUnauthorized},
+		{"unknown key", http.MethodGet, "/api/v1/tools", "wt_unknown", "", http.StatusUnauthorized},
+		{"revoked key", http.MethodGet, "/api/v1/tools", revoked.Key, "", http.StatusUnauthorized},
+		{"read key read", http.MethodGet, "/api/v1/tools", readKey, "", http.StatusOK},
+		{"read key process", http.MethodPost, "/api/v1/tools/base64/process", readKey, process, http.StatusForbidden},
+		{"process key process", http.MethodPost, "/api/v1/tools/base64/process", processKey, process, http.StatusOK},
+		{"process key admin", http.MethodGet, "/api/v1/admin/api-keys", processKey, "", http.StatusForbidden},
+		{"admin key admin", http.MethodGet, "/api/v1/admin/api-keys", adminKey, "", http.StatusOK},
+		{"admin key process", http.MethodPost, "/api/v1/tools/base64/process", adminKey, process, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := s.do(tt.method, tt.path, tt.key, tt.body)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d; body %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+}
+
+func TestAnonymousScopes(t *testing.T) {
+	s := newAuthTestServer(t, false)
+
+	if w := s.do(http.MethodGet, "/api/v1/tools", "", ""); w.Code != http.StatusOK {
+		t.Errorf("anonymous read: status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if w := s.do(http.MethodGet, "/api/v1/admin/api-keys", "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous admin: status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestSharedResourcesRequireWriteScope(t *testing.T) {
+	s := newAuthTestServer(t, false)
+
+	// Anyone may create a recipe
+	w := s.do(http.MethodPost, "/api/v1/recipes", "", `{"name":"Shared","steps":[{"toolId":"json"}]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("creating a recipe: status %d, body %s", w.Code, w.Body)
+	}
+	var recipe models.Recipe
+	if err := json.Unmarshal(w.Body.Bytes(), &recipe); err != nil {
+		t.Fatalf("decoding the recipe: %v", err)
+	}
+	recipePath := "/api/v1/recipes/" + recipe.ID
+	update := `{"name":"Replaced","steps":[{"toolId":"json"}]}`
+
+	processKey := s.apiKey(t, models.ScopeToolsRead, models.ScopeToolsProcess)
+	writeKey := s.apiKey(t, models.ScopeToolsWrite)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		key    string
+		body   string
+		status int
+	}{
+		{"anonymous replace recipe", http.MethodPut, recipePath, "", update, http.StatusUnauthorized},
+		{"anonymous delete recipe", http.MethodDelete, recipePath, "", "", http.StatusUnauthorized},
+		{"anonymous delete schema", http.MethodDelete, "/api/v1/schemas/any", "", "", http.StatusUnauthorized},
+		{"legacy anonymous delete recipe", http.MethodDelete, "/api/recipes/" + recipe.ID, "", "", http.StatusUnauthorized},
+		{"process key delete recipe", http.MethodDelete, recipePath, processKey, "", http.StatusForbidden},
+		{"process key delete schema", http.MethodDelete, "/api/v1/schemas/any", processKey, "", http.StatusForbidden},
+		{"write key replace recipe", http.MethodPut, recipePath, writeKey, update, http.StatusOK},
+		{"write key delete recipe", http.MethodDelete, recipePath, writeKey, "", http.StatusNoContent},
+		{"write key delete unknown schema", http.MethodDelete, "/api/v1/schemas/any", writeKey, "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := s.do(tt.method, tt.path, tt.key, tt.body)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d; body %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+}
+
+func TestFailedAuthenticationsAreLimited(t *testing.T) {
+	s := newLimitedAuthTestServer(t, false, middleware.Limit{Requests: 2, Period: time.Minute})
+	key := s.apiKey(t, models.ScopeToolsRead)
+
+	// httptest requests come from 192.0.2.1
+	for i := 0; i < 2; i++ {
+		if w := s.do(http.MethodGet, "/api/v1/tools", "wt_unknown", ""); w.Code != http.StatusUnauthorized {
+			t.Fatalf("failure %d: status = %d, want %d", i+1, w.Code, http.StatusUnauthorized)
+		}
+	}
+
+	for _, tt := range []struct{ name, key string }{{"unknown key", "wt_unknown"}, {"valid key", key}} {
+		w := s.do(http.MethodGet, "/api/v1/tools", tt.key, "")
+		if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "30" {
+			t.Errorf("%s after the limit: status %d, Retry-After %q; want 429, 30", tt.name, w.Code, w.Header().Get("Retry-After"))
+		}
+	}
+
+	if w := s.do(http.MethodGet, "/api/v1/tools", "", ""); w.Code != http.StatusOK {
+		t.Errorf("anonymous request after the limit: status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tools", nil)
+	req.RemoteAddr = "198.51.100.7:1234"
+	req.Header.Set("Authorization", "Bearer "+key)
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("valid key from another address: status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"web-tools-platform/backend/internal/config"
+	"web-tools-platform/backend/internal/handlers"
+	"web-tools-platform/backend/internal/services"
+)
+
+func TestLegacyAPIAnnouncesDeprecation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		legacy      bool
+		sunset      string
+		path        string
+		status      int
+		deprecation string
+		sunsetDate  string
+		link        string
+	}{
+		{"legacy route", true, "2027-06-30", "/api/openapi.json", http.StatusOK,
+			"@1792195200", "Wed, 30 Jun 2027 00:00:00 GMT", `</api/v1/openapi.json>; rel="successor-version"`},
+		{"legacy route without sunset", true, "", "/api/openapi.json", http.StatusOK,
+			"@1792195200", "", `</api/v1/openapi.json>; rel="successor-version"`},
+		{"v1 route", true, "2027-06-30", "/api/v1/openapi.json", http.StatusOK, "", "", ""},
+		{"legacy routes disabled", false, "2027-06-30", "/api/openapi.json", http.StatusNotFound, "", "", ""},
+	}
+
+	for _, tt := range tests {
+		cfg := config.Default()
+		cfg.API.Legacy = tt.legacy
+		cfg.API.LegacySunset = tt.sunset
+		router := gin.New()
+		setupRoutes(router, cfg, handlers.NewHandler(nil, services.DefaultLimits), handlers.NewAuthHandler(nil), noRateLimit)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.status)
+		}
+		for header, want := range map[string]string{"Deprecation": tt.deprecation, "Sunset": tt.sunsetDate, "Link": tt.link} {
+			if got := w.Header().Get(header); got != want {
+				t.Errorf("%s: %s = %q, want %q", tt.name, header, got, want)
+			}
+		}
+	}
+}
@@ -1,59 +1,167 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
-	"web-tools-platform/backend/internal/database"
+	"web-tools-platform/backend/internal/config"
 	"web-tools-platform/backend/internal/handlers"
+	"web-tools-platform/backend/internal/metrics"
 	"web-tools-platform/backend/internal/middleware"
+	"web-tools-platform/backend/internal/models"
+	"web-tools-platform/backend/internal/openapi"
+	"web-tools-platform/backend/internal/services"
+	"web-tools-platform/backend/internal/web"
 )
 
 func main() {
-	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		logrus.Warn("No .env file found, using system environment variables")
+	configFile := flag.String("config", "", "YAML or TOML configuration `file` (default $CONFIG_FILE), overridden by the environment")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration with secrets redacted and exit")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: server [flags] [migrate|apikey|openapi ...]\n\nflags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// Load configuration from the file, .env and the environment
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "config:", err)
+		os.Exit(1)
+	}
+
+	if *printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "print-config:", err)
+			os.Exit(1)
+		}
+		return
 	}
 
 	// Initialize logger
-	setupLogger()
+	setupLogger(cfg.LogLevel)
+
+	// Run database migrations instead of the server when requested
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(cfg.Database, flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "migrate:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Manage API keys instead of running the server when requested
+	if flag.Arg(0) == "apikey" {
+		if err := runAPIKey(cfg.Database, flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "apikey:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Print or check the OpenAPI document instead of running the server
+	if flag.Arg(0) == "openapi" {
+		if err := runOpenAPI(cfg, flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "openapi:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Configure tracing before the database so queries are traced
+	tracing, err := setupTracing(cfg.Tracing)
+	if err != nil {
+		logrus.Fatal("Failed to set up tracing:", err)
+	}
 
 	// Initialize database
-	db, err := database.Initialize()
+	db, err := openDatabase(cfg.Database)
 	if err != nil {
 		logrus.Fatal("Failed to initialize database:", err)
 	}
-	defer db.Close()
+
+	// Export connection pool statistics
+	if err := metrics.RegisterDB(db.DB, db.Driver()); err != nil {
+		logrus.Fatal("Failed to register database metrics:", err)
+	}
+
+	// Keep the tool catalog in sync with the registered processors
+	if err := db.SyncTools(context.Background(), services.Catalog()); err != nil {
+		logrus.Fatal("Failed to sync tool catalog:", err)
+	}
+
+	// Configure rate limiting
+	rateLimit, err := setupRateLimit(db, cfg.RateLimit)
+	if err != nil {
+		logrus.Fatal("Invalid rate limit configuration:", err)
+	}
+
+	// Limit failed authentications, which the API rate limit never sees
+	// since authentication runs first
+	authFailureLimit, authFailureStore, err := setupAuthFailureLimit(db, cfg.RateLimit)
+	if err != nil {
+		logrus.Fatal("Invalid rate limit configuration:", err)
+	}
+
+	// Configure OpenID Connect login
+	oidc, err := setupOIDC(cfg.Auth.OIDC)
+	if err != nil {
+		logrus.Fatal("Invalid OpenID Connect configuration:", err)
+	}
 
 	// Initialize Gin router
-	router := setupRouter()
+	router, err := setupRouter(cfg, setupAuth(db, oidc, cfg.Auth.Required, authFailureLimit, authFailureStore))
+	if err != nil {
+		logrus.Fatal("Invalid trusted proxies:", err)
+	}
 
 	// Initialize handlers
-	handler := handlers.NewHandler(db)
+	handler := handlers.NewHandler(db, services.Limits{
+		MaxInputBytes:   cfg.Tools.MaxInputBytes,
+		Timeout:         cfg.Tools.Timeout,
+		PipelineTimeout: cfg.Tools.PipelineTimeout,
+	})
+	authHandler := handlers.NewAuthHandler(oidc)
 
 	// Setup routes
-	setupRoutes(router, handler)
+	setupRoutes(router, cfg, handler, authHandler, rateLimit)
 
-	// Get port from environment
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	closers := []closer{tracing, {"database", db.Close}}
+	if cfg.Metrics.Enabled && cfg.Metrics.Listen != "" {
+		listener, err := net.Listen("tcp", cfg.Metrics.Listen)
+		if err != nil {
+			logrus.Fatal("Failed to listen for metrics:", err)
+		}
+		metricsServer := serveMetrics(listener, cfg.Server)
+		closers = append([]closer{{"metrics server", metricsServer.Close}}, closers...)
 	}
 
-	logrus.Info("Starting server on port ", port)
-	if err := router.Run(":" + port); err != nil {
-		logrus.Fatal("Failed to start server:", err)
+	// Serve until SIGINT or SIGTERM, then drain requests and close the database
+	server := newServer(router, cfg.Port, cfg.Server)
+	if err := serve(server, cfg.Server.ShutdownTimeout, closers...); err != nil {
+		logrus.WithError(err).Error("Server stopped with errors")
+		os.Exit(1)
 	}
 }
 
-func setupLogger() {
+func setupLogger(level string) {
 	// Set log level
-	logLevel := os.Getenv("LOG_LEVEL")
-	switch logLevel {
+	switch level {
 	case "debug":
 		logrus.SetLevel(logrus.DebugLevel)
 	case "info":
@@ -70,63 +178,190 @@ func setupLogger() {
 	logrus.SetFormatter(&logrus.JSONFormatter{})
 }
 
-func setupRouter() *gin.Engine {
+func setupRouter(cfg *config.Config, auth gin.HandlerFunc) (*gin.Engine, error) {
 	// Set Gin mode
-	env := os.Getenv("ENV")
-	if env == "production" {
+	if cfg.Env == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	router := gin.New()
 
-	// Add middleware
+	// Only configured proxies may set the client IP through X-Forwarded-For;
+	// otherwise clients could pick a fresh rate limit bucket per request
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, err
+	}
+
+	// Add middleware; metrics and tracing run outside Recovery to see panics
+	// as 500s, and tracing before RequestID so the two can be linked
 	router.Use(gin.Logger())
+	router.Use(middleware.Metrics())
+	router.Use(otelgin.Middleware(cfg.Tracing.ServiceName, otelgin.WithFilter(tracedRequest)))
 	router.Use(gin.Recovery())
 	router.Use(middleware.RequestID())
 	router.Use(middleware.Logger())
+	router.Use(middleware.ClientID())
 
 	// CORS configuration
 	corsConfig := cors.DefaultConfig()
-	corsOrigin := os.Getenv("CORS_ORIGIN")
-	if corsOrigin != "" {
-		corsConfig.AllowOrigins = []string{corsOrigin}
-	} else {
-		corsConfig.AllowOrigins = []string{"http://localhost:5173", "http://localhost:5174"}
-	}
-	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID"}
-	corsConfig.ExposeHeaders = []string{"X-Request-ID"}
+	corsConfig.AllowOrigins = cfg.CORSOrigins
+	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID", "X-Client-ID"}
+	corsConfig.ExposeHeaders = []string{
+		"X-Request-ID",
+		"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After",
+		"Deprecation", "Sunset", "Link",
+	}
 	corsConfig.AllowCredentials = true
 
 	router.Use(cors.New(corsConfig))
 
-	return router
+	// Authentication runs after CORS so preflight requests are not rejected
+	router.Use(auth)
+
+	return router, nil
 }
 
-func setupRoutes(router *gin.Engine, handler *handlers.Handler) {
-	// Health check
-	router.GET("/health", handler.HealthCheck)
+// API route prefixes. The unversioned prefix serves the v1 routes for
+// clients written before the API was versioned.
+const (
+	apiV1Prefix     = "/api/v1"
+	legacyAPIPrefix = "/api"
+)
+
+// legacyAPIDeprecated is when the unversioned routes were deprecated in
+// favour of /api/v1
+var legacyAPIDeprecated = time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+
+// legacySuccessor maps an unversioned API path to its /api/v1 route
+func legacySuccessor(path string) string {
+	return apiV1Prefix + strings.TrimPrefix(path, legacyAPIPrefix)
+}
+
+// setupRoutes registers every route. rateLimit applies to the API groups
+// only, so probes, metric scrapes and frontend assets are never limited; it
+// runs after authentication, as it keys on the principal.
+func setupRoutes(router *gin.Engine, cfg *config.Config, handler *handlers.Handler, authHandler *handlers.AuthHandler, rateLimit gin.HandlerFunc) {
+	// Health checks; /health is kept for existing clients
+	router.GET("/healthz", handler.Liveness)
+	router.GET("/readyz", handler.Readiness)
+	router.GET("/health", handler.Liveness)
+
+	// Prometheus metrics, unless they have their own listener
+	if cfg.Metrics.Enabled && cfg.Metrics.Listen == "" {
+		router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
+
+	// API routes under /api/v1, and unversioned under /api for clients
+	// written before versioning, with deprecation headers
+	v1 := handlers.V1
+	v1.LegacyToolErrors = cfg.API.LegacyToolErrors
+
+	docs := &apiDocs{}
+	registerAPI(router.Group(apiV1Prefix, rateLimit), handler.ForVersion(v1), authHandler, docs)
+	if cfg.API.Legacy {
+		sunset, _ := cfg.API.Sunset()
+		legacy := router.Group(legacyAPIPrefix, middleware.Deprecated(legacyAPIDeprecated, sunset, legacySuccessor), rateLimit)
+		registerAPI(legacy, handler.ForVersion(v1), authHandler, docs)
+	}
 
-	// API routes
-	api := router.Group("/api")
+	if err := docs.build(router.Routes()); err != nil {
+		logrus.WithError(err).Error("Failed to build the OpenAPI document")
+	}
+
+	// Serve the embedded frontend, falling back to index.html for
+	// client-side routes
+	frontend, err := web.New(web.Dist())
+	if err != nil {
+		logrus.WithError(err).Warn("Serving the API only")
+		router.NoRoute(web.NotFound)
+		return
+	}
+	logrus.WithField("files", frontend.Files()).Info("Serving embedded frontend")
+	router.NoRoute(frontend.Serve)
+}
+
+// registerAPI registers the API routes on api, the group of one version
+func registerAPI(api *gin.RouterGroup, handler *handlers.Handler, authHandler *handlers.AuthHandler, docs *apiDocs) {
+	// API documentation, generated by setupRoutes once every route is
+	// registered
+	api.GET("/openapi.json", docs.serve)
+	api.GET("/docs", openapi.SwaggerUI("Web Tools Platform API", api.BasePath()+"/openapi.json"))
+
+	// Scopes required by API routes: reads need tools:read, requests that
+	// run tools or change data need tools:process, and replacing or deleting
+	// data other users share needs tools:write
+	read := middleware.RequireScope(models.ScopeToolsRead)
+	process := middleware.RequireScope(models.ScopeToolsProcess)
+	write := middleware.RequireScope(models.ScopeToolsWrite)
+
+	// Login routes
+	authRoutes := api.Group("/auth")
 	{
-		// Tools routes
-		tools := api.Group("/tools")
-		{
-			tools.GET("", handler.GetTools)
-			tools.GET("/:toolId", handler.GetTool)
-			tools.POST("/:toolId/process", handler.ProcessTool)
-		}
+		authRoutes.GET("/login", authHandler.Login)
+		authRoutes.GET("/callback", authHandler.Callback)
+		authRoutes.POST("/logout", authHandler.Logout)
+		authRoutes.GET("/me", authHandler.Me)
+	}
 
-		// Settings routes
-		settings := api.Group("/settings")
-		{
-			settings.GET("", handler.GetSettings)
-			settings.POST("", handler.UpdateSettings)
-		}
+	// Tools routes
+	tools := api.Group("/tools")
+	{
+		tools.GET("", read, handler.GetTools)
+		tools.GET("/:toolId", read, handler.GetTool)
+		tools.POST("/:toolId/process", process, handler.ProcessTool)
+	}
+
+	// Pipeline routes
+	pipelines := api.Group("/pipelines")
+	{
+		pipelines.POST("/process", process, handler.ProcessPipeline)
 	}
 
-	// Serve static files (for production)
-	router.Static("/assets", "./dist/assets")
-	router.StaticFile("/", "./dist/index.html")
+	// Recipe routes
+	recipes := api.Group("/recipes")
+	{
+		recipes.GET("", read, handler.GetRecipes)
+		recipes.POST("", process, handler.CreateRecipe)
+		recipes.GET("/:recipeId", read, handler.GetRecipe)
+		recipes.PUT("/:recipeId", write, handler.UpdateRecipe)
+		recipes.DELETE("/:recipeId", write, handler.DeleteRecipe)
+		recipes.POST("/:recipeId/run", process, handler.RunRecipe)
+	}
+
+	// Protobuf schema routes
+	schemas := api.Group("/schemas")
+	{
+		schemas.GET("", read, handler.GetSchemas)
+		schemas.POST("", process, handler.CreateSchema)
+		schemas.GET("/:schemaId", read, handler.GetSchema)
+		schemas.DELETE("/:schemaId", write, handler.DeleteSchema)
+		schemas.POST("/:schemaId/decode", process, handler.DecodeWithSchema)
+	}
+
+	// History routes
+	history := api.Group("/history")
+	{
+		history.GET("", read, handler.GetHistory)
+		history.DELETE("", process, handler.ClearHistory)
+		history.GET("/search", read, handler.SearchHistory)
+		history.GET("/:id", read, handler.GetHistoryEntry)
+		history.DELETE("/:id", process, handler.DeleteHistoryEntry)
+	}
+
+	// Settings routes
+	settings := api.Group("/settings")
+	{
+		settings.GET("", read, handler.GetSettings)
+		settings.POST("", process, handler.UpdateSettings)
+		settings.PATCH("", process, handler.PatchSettings)
+	}
+
+	// Admin routes
+	admin := api.Group("/admin", middleware.RequireScope(models.ScopeAdmin))
+	{
+		admin.GET("/api-keys", handler.GetAPIKeys)
+		admin.POST("/api-keys", handler.CreateAPIKey)
+		admin.DELETE("/api-keys/:keyId", handler.RevokeAPIKey)
+	}
 }
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"web-tools-platform/backend/internal/config"
+	"web-tools-platform/backend/internal/handlers"
+	"web-tools-platform/backend/internal/services"
+)
+
+func TestMetricsRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		enabled bool
+		listen  string
+		want    int
+	}{
+		{"disabled by default", false, "", http.StatusNotFound},
+		{"enabled", true, "", http.StatusOK},
+		{"on its own listener", true, "127.0.0.1:9090", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		cfg := config.Default()
+		cfg.Metrics.Enabled = tt.enabled
+		cfg.Metrics.Listen = tt.listen
+		router := gin.New()
+		setupRoutes(router, cfg, handlers.NewHandler(nil, services.DefaultLimits), handlers.NewAuthHandler(nil), noRateLimit)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		if w.Code != tt.want {
+			t.Errorf("%s: GET /metrics status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestServeMetrics(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	server := serveMetrics(listener, config.Default().Server)
+	defer server.Close()
+
+	for path, want := range map[string]int{"/metrics": http.StatusOK, "/api/v1/tools": http.StatusNotFound} {
+		resp, err := http.Get("http://" + server.Addr + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("GET %s status = %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"web-tools-platform/backend/internal/config"
+	"web-tools-platform/backend/internal/database"
+)
+
+const migrateUsage = `usage: server migrate <command>
+
+commands:
+  up          apply all pending migrations
+  down [n]    roll back the last n applied migrations (default 1)
+  status      list migrations and whether they are applied`
+
+// runMigrate implements the "migrate" subcommand
+func runMigrate(dbConfig config.Database, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate command\n%s", migrateUsage)
+	}
+
+	db, err := database.Open(dbConfig.Driver, dbConfig.DataSource())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		if len(args) != 1 {
+			return fmt.Errorf("unexpected arguments\n%s", migrateUsage)
+		}
+		if err := db.Migrate(ctx); err != nil {
+			return err
+		}
+	case "down":
+		steps := 1
+		if len(args) > 2 {
+			return fmt.Errorf("unexpected arguments\n%s", migrateUsage)
+		}
+		if len(args) == 2 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of migrations: %s", args[1])
+			}
+		}
+		if err := db.Rollback(ctx, steps); err != nil {
+			return err
+		}
+	case "status":
+	default:
+		return fmt.Errorf("unknown migrate command: %s\n%s", args[0], migrateUsage)
+	}
+
+	return printMigrationStatus(ctx, db)
+}
+
+// openDatabase opens the configured database for the server and the apikey
+// subcommand. Pending migrations are applied when auto_migrate is set and
+// are an error otherwise, so the schema only changes on request.
+func openDatabase(dbConfig config.Database) (*database.DB, error) {
+	if dbConfig.AutoMigrate {
+		return database.Initialize(dbConfig.Driver, dbConfig.DataSource())
+	}
+
+	db, err := database.Open(dbConfig.Driver, dbConfig.DataSource())
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := db.MigrationStatus(context.Background())
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	var pending []int
+	for _, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, status.Version)
+		}
+	}
+	if len(pending) > 0 {
+		db.Close()
+		return nil, fmt.Errorf("%d pending migrations (%v), run \"server migrate up\" or set DB_AUTO_MIGRATE=true", len(pending), pending)
+	}
+
+	return db, nil
+}
+
+// printMigrationStatus writes a table of all migrations to stdout
+func printMigrationStatus(ctx context.Context, db *database.DB) error {
+	statuses, err := db.MigrationStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tSTATUS\tAPPLIED AT\tDESCRIPTION")
+	for _, status := range statuses {
+		state, appliedAt := "pending", "-"
+		if status.Applied {
+			state, appliedAt = "applied", status.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Version, state, appliedAt, status.Description)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"web-tools-platform/backend/internal/config"
+	"web-tools-platform/backend/internal/database"
+)
+
+func TestOpenDatabaseMigratesOnlyWhenAsked(t *testing.T) {
+	dbConfig := config.Database{Driver: database.DriverSQLite, Path: filepath.Join(t.TempDir(), "test.db")}
+
+	if _, err := openDatabase(dbConfig); err == nil || !strings.Contains(err.Error(), "migrate up") {
+		t.Fatalf("opening an empty database = %v, want an error asking to migrate", err)
+	}
+
+	dbConfig.AutoMigrate = true
+	db, err := openDatabase(dbConfig)
+	if err != nil {
+		t.Fatalf("opening with auto_migrate: %v", err)
+	}
+	if err := db.Rollback(context.Background(), 1); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	db.Close()
+
+	// A rollback stays in place until migrations are applied on purpose
+	dbConfig.AutoMigrate = false
+	_, err = openDatabase(dbConfig)
+	if err == nil {
+		t.Fatal("opening after a rollback succeeded, want the pending migration reported")
+	}
+	if want := "1 pending migrations"; !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %v, want it to contain %q", err, want)
+	}
+
+	dbConfig.AutoMigrate = true
+	db, err = openDatabase(dbConfig)
+	if err != nil {
+		t.Fatalf("reopening with auto_migrate: %v", err)
+	}
+	db.Close()
+
+	dbConfig.AutoMigrate = false
+	db, err = openDatabase(dbConfig)
+	if err != nil {
+		t.Fatalf("opening a migrated database: %v", err)
+	}
+	db.Close()
+}
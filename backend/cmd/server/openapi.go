@@ -0,0 +1,492 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"web-tools-platform/backend/internal/config"
+	"web-tools-platform/backend/internal/handlers"
+	"web-tools-platform/backend/internal/models"
+	"web-tools-platform/backend/internal/openapi"
+	"web-tools-platform/backend/internal/services"
+	"web-tools-platform/backend/internal/version"
+)
+
+const openAPIUsage = `usage: server openapi <command>
+
+commands:
+  print   print the OpenAPI document`
+
+// Query parameters shared by the history routes
+var (
+	historyFilterParams = []openapi.Parameter{
+		{Name: "tool", Description: "Only entries of this tool", Schema: &openapi.Schema{Type: "string"}},
+		{Name: "from", Description: "Only entries at or after this RFC 3339 time or YYYY-MM-DD date", Schema: &openapi.Schema{Type: "string"}},
+		{Name: "to", Description: "Only entries before this RFC 3339 time, or on or before this YYYY-MM-DD date", Schema: &openapi.Schema{Type: "string"}},
+	}
+	paginationParams = []openapi.Parameter{
+		{Name: "page", Description: "Page number, from 1", Schema: &openapi.Schema{Type: "integer", Default: 1}},
+		{Name: "limit", Description: "Entries per page, at most 100", Schema: &openapi.Schema{Type: "integer", Default: 20}},
+	}
+)
+
+// Bodies of routes that respond with gin.H, described inline
+var (
+	settingsSaved = struct {
+		Message  string                 `json:"message"`
+		Settings map[string]interface{} `json:"settings"`
+	}{}
+	historyCleared = struct {
+		Deleted int64 `json:"deleted"`
+	}{}
+)
+
+// apiRoutes documents every route registered by setupRoutes, keyed by method
+// and gin path. TestAPIRoutesDocumented fails when the two differ.
+var apiRoutes = map[string]openapi.Route{
+	"GET /healthz": {
+		Tag:       "health",
+		Summary:   "Liveness check with build information",
+		Responses: map[int]interface{}{http.StatusOK: models.HealthResponse{}},
+	},
+	"GET /health": {
+		Tag:        "health",
+		Summary:    "Liveness check, alias of /healthz",
+		Responses:  map[int]interface{}{http.StatusOK: models.HealthResponse{}},
+		Deprecated: true,
+	},
+	"GET /readyz": {
+		Tag:         "health",
+		Summary:     "Readiness check of the database and migrations",
+		Description: "Responds 503 when any check fails.",
+		Responses: map[int]interface{}{
+			http.StatusOK:                 models.ReadinessResponse{},
+			http.StatusServiceUnavailable: models.ReadinessResponse{},
+		},
+	},
+	"GET /metrics": {
+		Tag:     "health",
+		Summary: "Prometheus metrics",
+		Responses: map[int]interface{}{http.StatusOK: openapi.Body{
+			ContentType: "text/plain",
+			Schema:      &openapi.Schema{Type: "string"},
+		}},
+	},
+
+	"GET /api/v1/openapi.json": {
+		Tag:       "docs",
+		Summary:   "This OpenAPI document",
+		Responses: map[int]interface{}{http.StatusOK: openapi.Body{ContentType: "application/json", Schema: &openapi.Schema{Type: "object"}}},
+	},
+	"GET /api/v1/docs": {
+		Tag:       "docs",
+		Summary:   "Swagger UI for this document",
+		Responses: map[int]interface{}{http.StatusOK: openapi.Body{ContentType: "text/html", Schema: &openapi.Schema{Type: "string"}}},
+	},
+
+	"GET /api/v1/auth/login": {
+		Tag:         "auth",
+		Summary:     "Start OpenID Connect login",
+		Description: "Redirects to the provider. Responds 404 when login is not configured.",
+		Query: []openapi.Parameter{
+			{Name: "redirect", Description: "Local path to return to after login", Schema: &openapi.Schema{Type: "string", Default: "/"}},
+		},
+		Responses: map[int]interface{}{http.StatusFound: nil},
+	},
+	"GET /api/v1/auth/callback": {
+		Tag:         "auth",
+		Summary:     "Complete login and start a session",
+		Description: "Called by the provider; sets the session cookie and redirects to the path given to login.",
+		Query: []openapi.Parameter{
+			{Name: "code", Schema: &openapi.Schema{Type: "string"}},
+			{Name: "state", Schema: &openapi.Schema{Type: "string"}},
+			{Name: "error", Schema: &openapi.Schema{Type: "string"}},
+			{Name: "error_description", Schema: &openapi.Schema{Type: "string"}},
+		},
+		Responses: map[int]interface{}{http.StatusFound: nil},
+	},
+	"POST /api/v1/auth/logout": {
+		Tag:       "auth",
+		Summary:   "End the session",
+		Responses: map[int]interface{}{http.StatusNoContent: nil},
+	},
+	"GET /api/v1/auth/me": {
+		Tag:       "auth",
+		Summary:   "Current user, API key or anonymous caller",
+		Responses: map[int]interface{}{http.StatusOK: models.Principal{}},
+	},
+
+	"GET /api/v1/tools": {
+		Tag:       "tools",
+		Summary:   "List tools",
+		Scope:     models.ScopeToolsRead,
+		Responses: map[int]interface{}{http.StatusOK: []models.Tool{}},
+	},
+	"GET /api/v1/tools/:toolId": {
+		Tag:         "tools",
+		Summary:     "Get a tool",
+		Description: "Includes the settings schema that process requests are validated against.",
+		Scope:       models.ScopeToolsRead,
+		Responses:   map[int]interface{}{http.StatusOK: models.Tool{}},
+	},
+	"POST /api/v1/tools/:toolId/process": {
+		Tag:         "tools",
+		Summary:     "Run a tool",
+		Description: "The settings of each tool are described by its own operation under /api/v1/tools/{id}/process.",
+		Scope:       models.ScopeToolsProcess,
+		Request:     models.ToolRequest{},
+		Responses:   processResponses,
+	},
+
+	"POST /api/v1/pipelines/process": {
+		Tag:       "pipelines",
+		Summary:   "Run input through several tools",
+		Scope:     models.ScopeToolsProcess,
+		Request:   models.PipelineRequest{},
+		Responses: map[int]interface{}{http.StatusOK: models.ToolResponse{}},
+	},
+
+	"GET /api/v1/recipes": {
+		Tag:       "recipes",
+		Summary:   "List recipes",
+		Scope:     models.ScopeToolsRead,
+		Responses: map[int]interface{}{http.StatusOK: []models.Recipe{}},
+	},
+	"POST /api/v1/recipes": {
+		Tag:       "recipes",
+		Summary:   "Create a recipe",
+		Scope:     models.ScopeToolsProcess,
+		Request:   models.RecipeRequest{},
+		Responses: map[int]interface{}{http.StatusCreated: models.Recipe{}},
+	},
+	"GET /api/v1/recipes/:recipeId": {
+		Tag:       "recipes",
+		Summary:   "Get a recipe",
+		Scope:     models.ScopeToolsRead,
+		Responses: map[int]interface{}{http.StatusOK: models.Recipe{}},
+	},
+	"PUT /api/v1/recipes/:recipeId": {
+		Tag:       "recipes",
+		Summary:   "Replace a recipe",
+		Scope:     models.ScopeToolsWrite,
+		Request:   models.RecipeRequest{},
+		Responses: map[int]interface{}{http.StatusOK: models.Recipe{}},
+	},
+	"DELETE /api/v1/recipes/:recipeId": {
+		Tag:       "recipes",
+		Summary:   "Delete a recipe",
+		Scope:     models.ScopeToolsWrite,
+		Responses: map[int]interface{}{http.StatusNoContent: nil},
+	},
+	"POST /api/v1/recipes/:recipeId/run": {
+		Tag:         "recipes",
+		Summary:     "Run a recipe",
+		Description: "Only the input of the request is used.",
+		Scope:       models.ScopeToolsProcess,
+		Request:     models.ToolRequest{},
+		Responses:   map[int]interface{}{http.StatusOK: models.ToolResponse{}},
+	},
+
+	"GET /api/v1/schemas": {
+		Tag:       "schemas",
+		Summary:   "List protobuf schemas",
+		Scope:     models.ScopeToolsRead,
+		Responses: map[int]interface{}{http.StatusOK: []models.ProtoSchema{}},
+	},
+	"POST /api/v1/schemas": {
+		Tag:         "schemas",
+		Summary:     "Upload a protobuf schema",
+		Description: "Upload .proto sources or a single compiled FileDescriptorSet in one or more file fields.",
+		Scope:       models.ScopeToolsProcess,
+		Request: openapi.Body{ContentType: "multipart/form-data", Schema: &openapi.Schema{
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"name": {Type: "string", Description: "Schema name, the first file name by default"},
+				"file": {Type: "array", Items: &openapi.Schema{Type: "string", Format: "binary"}},
+			},
+			Required: []string{"file"},
+		}},
+		Responses: map[int]interface{}{http.StatusCreated: models.ProtoSchema{}},
+	},
+	"GET /api/v1/schemas/:schemaId": {
+		Tag:       "schemas",
+		Summary:   "Get a protobuf schema",
+		Scope:     models.ScopeToolsRead,
+		Responses: map[int]interface{}{http.StatusOK: models.ProtoSchema{}},
+	},
+	"DELETE /api/v1/schemas/:schemaId": {
+		Tag:       "schemas",
+		Summary:   "Delete a protobuf schema",
+		Scope:     models.ScopeToolsWrite,
+		Responses: map[int]interface{}{http.StatusNoContent: nil},
+	},
+	"POST /api/v1/schemas/:schemaId/decode": {
+		Tag:       "schemas",
+		Summary:   "Convert a protobuf payload with a stored schema",
+		Scope:     models.ScopeToolsProcess,
+		Request:   models.SchemaDecodeRequest{},
+		Responses: processResponses,
+	},
+
+	"GET /api/v1/history": {
+		Tag:       "history",
+		Summary:   "List tool history",
+		Scope:     models.ScopeToolsRead,
+		Query:     append(append([]openapi.Parameter{}, historyFilterParams...), paginationParams...),
+		Responses: map[int]interface{}{http.StatusOK: models.HistoryPage{}},
+	},
+	"DELETE /api/v1/history": {
+		Tag:       "history",
+		Summary:   "Clear tool history",
+		Scope:     models.ScopeToolsProcess,
+		Query:     historyFilterParams,
+		Responses: map[int]interface{}{http.StatusOK: historyCleared},
+	},
+	"GET /api/v1/history/search": {
+		Tag:         "history",
+		Summary:     "Search tool history",
+		Description: "Matched terms are marked with <mark> in the snippets.",
+		Scope:       models.ScopeToolsRead,
+		Query: append(append([]openapi.Parameter{
+			{Name: "q", Description: "Search terms", Required: true, Schema: &openapi.Schema{Type: "string"}},
+		}, historyFilterParams...), paginationParams...),
+		Responses: map[int]interface{}{http.StatusOK: models.HistorySearchPage{}},
+	},
+	"GET /api/v1/history/:id": {
+		Tag:       "history",
+		Summary:   "Get a history entry",
+		Scope:     models.ScopeToolsRead,
+		Path:      map[string]*openapi.Schema{"id": {Type: "integer"}},
+		Responses: map[int]interface{}{http.StatusOK: models.ToolHistoryEntry{}},
+	},
+	"DELETE /api/v1/history/:id": {
+		Tag:       "history",
+		Summary:   "Delete a history entry",
+		Scope:     models.ScopeToolsProcess,
+		Path:      map[string]*openapi.Schema{"id": {Type: "integer"}},
+		Responses: map[int]interface{}{http.StatusNoContent: nil},
+	},
+
+	"GET /api/v1/settings": {
+		Tag:       "settings",
+		Summary:   "Get settings, merged with the defaults",
+		Scope:     models.ScopeToolsRead,
+		Responses: map[int]interface{}{http.StatusOK: userSettingsBody},
+	},
+	"POST /api/v1/settings": {
+		Tag:       "settings",
+		Summary:   "Replace all saved settings",
+		Scope:     models.ScopeToolsProcess,
+		Request:   userSettingsBody,
+		Responses: map[int]interface{}{http.StatusOK: settingsSaved},
+	},
+	"PATCH /api/v1/settings": {
+		Tag:       "settings",
+		Summary:   "Update the given settings",
+		Scope:     models.ScopeToolsProcess,
+		Request:   userSettingsBody,
+		Responses: map[int]interface{}{http.StatusOK: settingsSaved},
+	},
+
+	"GET /api/v1/admin/api-keys": {
+		Tag:       "admin",
+		Summary:   "List API keys",
+		Scope:     models.ScopeAdmin,
+		Responses: map[int]interface{}{http.StatusOK: []models.APIKey{}},
+	},
+	"POST /api/v1/admin/api-keys": {
+		Tag:         "admin",
+		Summary:     "Issue an API key",
+		Description: "The key is only returned in this response.",
+		Scope:       models.ScopeAdmin,
+		Request:     models.APIKeyRequest{},
+		Responses:   map[int]interface{}{http.StatusCreated: models.CreatedAPIKey{}},
+	},
+	"DELETE /api/v1/admin/api-keys/:keyId": {
+		Tag:       "admin",
+		Summary:   "Revoke an API key",
+		Scope:     models.ScopeAdmin,
+		Responses: map[int]interface{}{http.StatusNoContent: nil},
+	},
+}
+
+// processResponses are the responses of running a single tool or decoding
+// with a stored schema. Errors carry TOOL_NOT_FOUND, INVALID_INPUT,
+// INVALID_SETTINGS, UNSUPPORTED_MODE, INPUT_TOO_LARGE or TOOL_TIMEOUT with
+// their details.
+var processResponses = map[int]interface{}{
+	http.StatusOK:                    models.ToolResponse{},
+	http.StatusBadRequest:            models.ErrorResponse{},
+	http.StatusNotFound:              models.ErrorResponse{},
+	http.StatusRequestEntityTooLarge: models.ErrorResponse{},
+	http.StatusUnprocessableEntity:   models.ErrorResponse{},
+	http.StatusGatewayTimeout:        models.ErrorResponse{},
+}
+
+// userSettingsBody refers to the UserSettings component added by buildOpenAPI
+var userSettingsBody = openapi.Body{ContentType: "application/json", Schema: openapi.Ref("UserSettings")}
+
+// apiDescription introduces the document
+const apiDescription = `Developer tools for encoding, formatting and inspecting data.
+
+Every route under /api/v1 is also served under /api for older clients. Those
+responses carry Deprecation and Sunset headers and a successor-version link.`
+
+// apiTags describes the tags of apiRoutes, in display order
+var apiTags = []openapi.Tag{
+	{Name: "tools", Description: "Tool catalog and processing"},
+	{Name: "pipelines", Description: "Chains of tools"},
+	{Name: "recipes", Description: "Saved pipelines"},
+	{Name: "schemas", Description: "Protobuf schemas for decoding"},
+	{Name: "history", Description: "Tool usage history"},
+	{Name: "settings", Description: "User preferences"},
+	{Name: "auth", Description: "OpenID Connect login"},
+	{Name: "admin", Description: "API key management"},
+	{Name: "health", Description: "Health checks and metrics"},
+	{Name: "docs", Description: "API documentation"},
+}
+
+// buildOpenAPI documents the registered routes. Every tool also gets an
+// operation of its own under /api/v1/tools/{id}/process describing its
+// settings.
+func buildOpenAPI(routes gin.RoutesInfo) *openapi.Document {
+	generator := openapi.NewGenerator(openapi.Info{
+		Title:       "Web Tools Platform API",
+		Description: apiDescription,
+		Version:     version.Version,
+	})
+	for _, tag := range apiTags {
+		generator.AddTag(tag.Name, tag.Description)
+	}
+	generator.AddSecurityScheme("apiKey", openapi.SecurityScheme{
+		Type:        "http",
+		Scheme:      "bearer",
+		Description: "API key issued through /api/v1/admin/api-keys or `server apikey create`",
+	})
+	generator.AddSecurityScheme("session", openapi.SecurityScheme{
+		Type:        "apiKey",
+		In:          "cookie",
+		Name:        "session",
+		Description: "Session cookie set by the OpenID Connect login",
+	})
+	generator.AllowAnonymous()
+
+	generator.AddSchema("UserSettings", openapi.SettingsSchema(services.UserSettingsSchema()))
+
+	for _, route := range documentedRoutes(routes) {
+		if doc, ok := apiRoutes[route.Method+" "+route.Path]; ok {
+			generator.Add(route.Method, route.Path, doc)
+		}
+	}
+
+	// Concrete paths take precedence over the templated one, so clients see
+	// the settings each tool accepts
+	schemas := services.SettingsSchemas()
+	toolIDs := make([]string, 0, len(schemas))
+	for id := range schemas {
+		toolIDs = append(toolIDs, id)
+	}
+	sort.Strings(toolIDs)
+
+	for _, id := range toolIDs {
+		name := schemaName(id) + "Settings"
+		settings := generator.AddSchema(name, openapi.SettingsSchema(schemas[id]))
+		request := generator.AddSchema(schemaName(id)+"Request", &openapi.Schema{
+			Type:       "object",
+			Properties: map[string]*openapi.Schema{"input": {Type: "string"}, "settings": settings},
+			Required:   []string{"input"},
+		})
+
+		generator.Add(http.MethodPost, apiV1Prefix+"/tools/"+id+"/process", openapi.Route{
+			Tag:       "tools",
+			Summary:   "Run the " + id + " tool",
+			Scope:     models.ScopeToolsProcess,
+			Request:   openapi.Body{ContentType: "application/json", Schema: request},
+			Responses: processResponses,
+		})
+	}
+
+	return generator.Document()
+}
+
+// documentedRoutes leaves out the unversioned aliases of /api/v1 routes,
+// which are documented by their versioned route
+func documentedRoutes(routes gin.RoutesInfo) gin.RoutesInfo {
+	var documented gin.RoutesInfo
+	for _, route := range routes {
+		legacy := strings.HasPrefix(route.Path, legacyAPIPrefix+"/") && !strings.HasPrefix(route.Path, apiV1Prefix+"/")
+		if !legacy {
+			documented = append(documented, route)
+		}
+	}
+	return documented
+}
+
+// schemaName turns a tool ID such as "base64" into a component name prefix
+// such as "Base64"
+func schemaName(id string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(id, func(r rune) bool { return r == '-' || r == '_' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// apiDocs serves the OpenAPI document, built once every route is registered
+type apiDocs struct {
+	spec []byte
+}
+
+// build generates the document for routes, warning about undocumented ones
+func (d *apiDocs) build(routes gin.RoutesInfo) error {
+	undocumented, _ := openapi.Drift(documentedRoutes(routes), apiRoutes)
+	for _, route := range undocumented {
+		logrus.WithField("route", route).Warn("Route missing from the OpenAPI document")
+	}
+
+	spec, err := json.Marshal(buildOpenAPI(routes))
+	if err != nil {
+		return err
+	}
+	d.spec = spec
+	return nil
+}
+
+func (d *apiDocs) serve(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", d.spec)
+}
+
+// runOpenAPI implements the "openapi" subcommand. It registers the routes
+// with every optional route enabled, without opening the database.
+func runOpenAPI(cfg *config.Config, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected one command\n%s", openAPIUsage)
+	}
+
+	// Only the routes matter here, not the startup messages
+	gin.SetMode(gin.ReleaseMode)
+	logrus.SetLevel(logrus.ErrorLevel)
+
+	routesConfig := *cfg
+	routesConfig.Metrics.Enabled = true
+	routesConfig.Metrics.Listen = ""
+	routesConfig.API.Legacy = true
+	router := gin.New()
+	setupRoutes(router, &routesConfig, handlers.NewHandler(nil, services.DefaultLimits), handlers.NewAuthHandler(nil), noRateLimit)
+
+	switch args[0] {
+	case "print":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(buildOpenAPI(router.Routes()))
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], openAPIUsage)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"web-tools-platform/backend/internal/config"
+	"web-tools-platform/backend/internal/handlers"
+	"web-tools-platform/backend/internal/openapi"
+	"web-tools-platform/backend/internal/services"
+)
+
+// testRouter registers every route, with the optional ones enabled, without
+// a database
+func testRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.Default()
+	cfg.Metrics.Enabled = true
+	cfg.API.Legacy = true
+
+	router := gin.New()
+	setupRoutes(router, cfg, handlers.NewHandler(nil, services.DefaultLimits), handlers.NewAuthHandler(nil), noRateLimit)
+	return router
+}
+
+func TestAPIRoutesDocumented(t *testing.T) {
+	router := testRouter(t)
+
+	undocumented, unregistered := openapi.Drift(documentedRoutes(router.Routes()), apiRoutes)
+	for _, route := range undocumented {
+		t.Errorf("route %s is registered but missing from apiRoutes", route)
+	}
+	for _, route := range unregistered {
+		t.Errorf("route %s is in apiRoutes but not registered", route)
+	}
+
+	for _, ref := range buildOpenAPI(router.Routes()).UnresolvedRefs() {
+		t.Errorf("unresolved schema reference %s", ref)
+	}
+}
+
+func TestLegacyRoutesMirrorV1(t *testing.T) {
+	registered := map[string]bool{}
+	for _, route := range testRouter(t).Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+
+	for route := range registered {
+		method, path, _ := strings.Cut(route, " ")
+		if strings.HasPrefix(path, apiV1Prefix+"/") && !registered[method+" "+legacyAPIPrefix+strings.TrimPrefix(path, apiV1Prefix)] {
+			t.Errorf("route %s has no unversioned alias", route)
+		}
+	}
+}
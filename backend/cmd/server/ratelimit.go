@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"web-tools-platform/backend/internal/config"
+	"web-tools-platform/backend/internal/database"
+	"web-tools-platform/backend/internal/middleware"
+)
+
+// processRoutes run tool processors and get the stricter process limit,
+// under every API prefix
+var processRoutes = []string{
+	"POST /tools/:toolId/process",
+	"POST /pipelines/process",
+	"POST /recipes/:recipeId/run",
+}
+
+// noRateLimit stands in for the rate limiter when limiting is disabled
+func noRateLimit(c *gin.Context) {
+	c.Next()
+}
+
+// setupRateLimit builds the rate limiting middleware. A default limit of
+// "off" disables it; the database store shares buckets between instances.
+func setupRateLimit(db *database.DB, rateLimit config.RateLimit) (gin.HandlerFunc, error) {
+	if rateLimit.Default == "off" {
+		logrus.Warn("Rate limiting disabled")
+		return noRateLimit, nil
+	}
+
+	limits := middleware.RateLimitConfig{Routes: map[string]middleware.Limit{}}
+
+	var err error
+	if limits.Default, err = parseLimit(rateLimit.Default); err != nil {
+		return nil, fmt.Errorf("default limit: %w", err)
+	}
+
+	processLimit, err := parseLimit(rateLimit.Process)
+	if err != nil {
+		return nil, fmt.Errorf("process limit: %w", err)
+	}
+	for _, route := range processRoutes {
+		method, path, _ := strings.Cut(route, " ")
+		for _, prefix := range []string{apiV1Prefix, legacyAPIPrefix} {
+			limits.Routes[method+" "+prefix+path] = processLimit
+		}
+	}
+
+	if limits.Store, err = rateLimitStore(db, rateLimit.Store); err != nil {
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"default": limits.Default.String(),
+		"process": processLimit.String(),
+		"store":   rateLimit.Store,
+	}).Info("Rate limiting enabled")
+
+	return middleware.RateLimit(limits), nil
+}
+
+// setupAuthFailureLimit returns the limit on failed authentications per
+// client IP, zero when it is "off", and the store counting them
+func setupAuthFailureLimit(db *database.DB, rateLimit config.RateLimit) (middleware.Limit, middleware.RateLimitStore, error) {
+	if rateLimit.AuthFailures == "off" {
+		logrus.Warn("Failed authentication limit disabled")
+		return middleware.Limit{}, nil, nil
+	}
+
+	limit, err := parseLimit(rateLimit.AuthFailures)
+	if err != nil {
+		return middleware.Limit{}, nil, fmt.Errorf("auth failures limit: %w", err)
+	}
+	store, err := rateLimitStore(db, rateLimit.Store)
+	if err != nil {
+		return middleware.Limit{}, nil, err
+	}
+
+	return limit, store, nil
+}
+
+// parseLimit reads a configured limit for the middleware
+func parseLimit(s string) (middleware.Limit, error) {
+	limit, err := config.ParseLimit(s)
+	return middleware.Limit(limit), err
+}
+
+// rateLimitStore creates the named token bucket store
+func rateLimitStore(db *database.DB, name string) (middleware.RateLimitStore, error) {
+	switch name {
+	case "memory":
+		return middleware.NewMemoryStore(), nil
+	case "database":
+		return middleware.NewDatabaseStore(db), nil
+	default:
+		return nil, fmt.Errorf("unsupported store %q", name)
+	}
+}
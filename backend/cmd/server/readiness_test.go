@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"web-tools-platform/backend/internal/config"
+	"web-tools-platform/backend/internal/database"
+	"web-tools-platform/backend/internal/handlers"
+	"web-tools-platform/backend/internal/models"
+	"web-tools-platform/backend/internal/services"
+)
+
+// readiness calls /readyz against db and decodes the response
+func readiness(t *testing.T, db *database.DB) (int, models.ReadinessResponse) {
+	t.Helper()
+	router := gin.New()
+	setupRoutes(router, config.Default(), handlers.NewHandler(db, services.DefaultLimits), handlers.NewAuthHandler(nil), noRateLimit)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var response models.ReadinessResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decoding the response: %v; body %s", err, w.Body)
+	}
+	return w.Code, response
+}
+
+func TestReadiness(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := database.Initialize(database.DriverSQLite, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("opening the database: %v", err)
+	}
+	defer db.Close()
+
+	if status, response := readiness(t, db); status != http.StatusOK || response.Status != "healthy" {
+		t.Fatalf("migrated database: status = %d %q, want %d healthy; checks %+v", status, response.Status, http.StatusOK, response.Checks)
+	}
+
+	if err := db.Rollback(context.Background(), 1); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	status, response := readiness(t, db)
+	if status != http.StatusServiceUnavailable || response.Status != "unhealthy" {
+		t.Errorf("pending migration: status = %d %q, want %d unhealthy", status, response.Status, http.StatusServiceUnavailable)
+	}
+	if check := response.Checks["migrations"]; check.Status != "unhealthy" || !strings.Contains(check.Error, "1 pending migrations") {
+		t.Errorf("pending migration: migrations check = %+v, want the pending migration reported", check)
+	}
+	if check := response.Checks["database"]; check.Status != "healthy" {
+		t.Errorf("pending migration: database check = %+v, want healthy", check)
+	}
+
+	db.Close()
+	status, response = readiness(t, db)
+	if status != http.StatusServiceUnavailable || response.Status != "unhealthy" {
+		t.Errorf("closed database: status = %d %q, want %d unhealthy", status, response.Status, http.StatusServiceUnavailable)
+	}
+	if check := response.Checks["database"]; check.Status != "unhealthy" || check.Error != "ping failed" {
+		t.Errorf("closed database: database check = %+v, want a failed ping", check)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"web-tools-platform/backend/internal/config"
+	"web-tools-platform/backend/internal/metrics"
+	"web-tools-platform/backend/internal/version"
+)
+
+// closer releases a resource once the server has stopped serving requests
+type closer struct {
+	name  string
+	close func() error
+}
+
+// newServer builds the HTTP server for handler
+func newServer(handler http.Handler, port int, timeouts config.Server) *http.Server {
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           handler,
+		ReadHeaderTimeout: timeouts.ReadHeaderTimeout,
+		ReadTimeout:       timeouts.ReadTimeout,
+		WriteTimeout:      timeouts.WriteTimeout,
+		IdleTimeout:       timeouts.IdleTimeout,
+	}
+}
+
+// serveMetrics serves /metrics alone on listener, away from the API, until
+// the returned server is closed
+func serveMetrics(listener net.Listener, timeouts config.Server) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	server := newServer(mux, 0, timeouts)
+	server.Addr = listener.Addr().String()
+
+	logrus.WithField("addr", server.Addr).Info("Serving metrics")
+	go func() {
+		if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			logrus.WithError(err).Error("Metrics server failed")
+		}
+	}()
+
+	return server
+}
+
+// serve runs server until it fails or the process receives SIGINT or
+// SIGTERM. On a signal it stops accepting connections and waits up to
+// shutdownTimeout for in-flight requests before cutting them off. The
+// closers then run in order, whether or not serving failed. A second signal
+// during shutdown exits immediately.
+func serve(server *http.Server, shutdownTimeout time.Duration, closers ...closer) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logrus.WithFields(logrus.Fields{
+		"addr":          server.Addr,
+		"read_timeout":  server.ReadTimeout.String(),
+		"write_timeout": server.WriteTimeout.String(),
+		"idle_timeout":  server.IdleTimeout.String(),
+		"version":       version.Version,
+		"commit":        version.Commit,
+		"build_time":    version.BuildTime,
+	}).Info("Starting server")
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+		err = fmt.Errorf("server failed: %w", err)
+	case <-ctx.Done():
+		// Restore default signal handling so a second signal kills the process
+		stop()
+		err = shutdown(server, shutdownTimeout)
+	}
+
+	for _, c := range closers {
+		if closeErr := c.close(); closeErr != nil {
+			logrus.WithError(closeErr).WithField("resource", c.name).Error("Failed to close resource")
+			err = errors.Join(err, closeErr)
+		}
+	}
+
+	return err
+}
+
+// shutdown drains in-flight requests, closing any still open at the deadline
+func shutdown(server *http.Server, timeout time.Duration) error {
+	logrus.WithField("timeout", timeout.String()).Info("Shutting down server, draining in-flight requests")
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		logrus.WithError(err).Warn("Shutdown deadline exceeded, closing remaining connections")
+		server.Close()
+		return fmt.Errorf("shutdown: %w", err)
+	}
+
+	logrus.Info("Server stopped")
+	return nil
+}
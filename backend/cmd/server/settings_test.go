@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+func TestSaveSettingsReportsInvalidFields(t *testing.T) {
+	s := newAuthTestServer(t, false)
+
+	for _, method := range []string{http.MethodPost, http.MethodPatch} {
+		w := s.do(method, "/api/v1/settings", "", `{"theme":"blue","autoSave":"yes"}`)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want %d; body %s", method, w.Code, http.StatusBadRequest, w.Body)
+		}
+
+		var response models.ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("%s: decoding the response: %v", method, err)
+		}
+		details, _ := response.Details.(map[string]interface{})
+		fields, _ := details["fields"].(map[string]interface{})
+		if response.Code != "INVALID_SETTINGS" || len(fields) != 2 || fields["theme"] == nil || fields["autoSave"] == nil {
+			t.Errorf("%s: response = %+v, want both invalid settings in details.fields", method, response)
+		}
+	}
+}
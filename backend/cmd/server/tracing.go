@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+
+	"web-tools-platform/backend/internal/config"
+)
+
+// tracingFlushTimeout bounds how long buffered spans are flushed on shutdown
+const tracingFlushTimeout = 5 * time.Second
+
+// setupTracing installs the global tracer provider and the W3C trace context
+// propagator. Incoming traceparent headers are honoured even when the
+// exporter is "none", so the request ID can still be tied to the caller's
+// trace. The returned closer flushes buffered spans.
+func setupTracing(tracing config.Tracing) (closer, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	noop := closer{"tracing", func() error { return nil }}
+	if tracing.Exporter == "none" {
+		return noop, nil
+	}
+
+	exporter, closeOutput, err := newSpanExporter(tracing)
+	if err != nil {
+		return noop, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(tracing.ServiceName),
+		)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(tracing.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	logrus.WithFields(logrus.Fields{
+		"exporter":     tracing.Exporter,
+		"service":      tracing.ServiceName,
+		"sample_ratio": tracing.SampleRatio,
+	}).Info("Tracing enabled")
+
+	return closer{"tracing", func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingFlushTimeout)
+		defer cancel()
+
+		if err := provider.Shutdown(ctx); err != nil {
+			return err
+		}
+		return closeOutput()
+	}}, nil
+}
+
+// newSpanExporter creates the configured exporter and a function closing
+// its output file, if any
+func newSpanExporter(tracing config.Tracing) (sdktrace.SpanExporter, func() error, error) {
+	noClose := func() error { return nil }
+
+	switch tracing.Exporter {
+	case "otlp":
+		exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(tracing.Endpoint))
+		return exporter, noClose, err
+	case "stdout":
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		return exporter, noClose, err
+	case "file":
+		file, err := os.OpenFile(tracing.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, err
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+		return exporter, file.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported exporter %q", tracing.Exporter)
+	}
+}
+
+// tracedRequest keeps health checks and metric scrapes out of traces
+func tracedRequest(r *http.Request) bool {
+	switch r.URL.Path {
+	case "/health", "/healthz", "/readyz", "/metrics":
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"web-tools-platform/backend/internal/config"
+)
+
+// exportedSpan is the part of a span written by the file exporter that the
+// tests look at
+type exportedSpan struct {
+	Name        string
+	SpanContext struct{ TraceID string }
+	Attributes  []struct {
+		Key   string
+		Value struct{ Value interface{} }
+	}
+}
+
+// attribute returns the value of the span attribute key, or nil
+func (s exportedSpan) attribute(key string) interface{} {
+	for _, attr := range s.Attributes {
+		if attr.Key == key {
+			return attr.Value.Value
+		}
+	}
+	return nil
+}
+
+// The global tracer provider can only be installed once per process, so
+// this is the only test that enables tracing
+func TestTracingContinuesCallerTrace(t *testing.T) {
+	tracing := config.Default().Tracing
+	tracing.Exporter = "file"
+	tracing.File = filepath.Join(t.TempDir(), "spans.json")
+	flush, err := setupTracing(tracing)
+	if err != nil {
+		t.Fatalf("setupTracing: %v", err)
+	}
+
+	s := newAuthTestServer(t, false)
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tools/base64/process", strings.NewReader(`{"input":"hello","settings":{"mode":"encode"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	req.Header.Set("X-Request-ID", "traced-request")
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("process status = %d, want %d; body %s", w.Code, http.StatusOK, w.Body)
+	}
+	s.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	if err := flush.close(); err != nil {
+		t.Fatalf("flushing spans: %v", err)
+	}
+	file, err := os.Open(tracing.File)
+	if err != nil {
+		t.Fatalf("opening the span file: %v", err)
+	}
+	defer file.Close()
+
+	var spans []exportedSpan
+	for decoder := json.NewDecoder(file); ; {
+		var span exportedSpan
+		if err := decoder.Decode(&span); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatalf("decoding a span: %v", err)
+		}
+		spans = append(spans, span)
+	}
+
+	var requestSpans, serviceSpans int
+	for _, span := range spans {
+		if span.SpanContext.TraceID != traceID {
+			t.Errorf("span %q trace ID = %s, want the caller's %s", span.Name, span.SpanContext.TraceID, traceID)
+		}
+		if id := span.attribute("http.request_id"); id != nil {
+			requestSpans++
+			if id != "traced-request" {
+				t.Errorf("span %q http.request_id = %v, want traced-request", span.Name, id)
+			}
+		}
+		if span.Name == "Service.ProcessTool" {
+			serviceSpans++
+		}
+	}
+	// One request span for the process call, none for the health check
+	if requestSpans != 1 || serviceSpans != 1 {
+		t.Errorf("got %d request and %d service spans, want 1 of each", requestSpans, serviceSpans)
+	}
+}
@@ -0,0 +1,242 @@
+// Package auth implements OpenID Connect login for the web UI and the signed
+// session cookies that carry the logged-in user between requests.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+// Cookie names used by the login flow
+const (
+	SessionCookie = "session"
+	StateCookie   = "oidc_state"
+)
+
+// Purposes of signed values
+const (
+	sessionPurpose = "session"
+	statePurpose   = "oidc_state"
+)
+
+// StateTTL bounds how long a user may take to log in at the provider
+const StateTTL = 10 * time.Minute
+
+// MinSessionSecretLength is the minimum length of the session secret
+const MinSessionSecretLength = 32
+
+// Config configures OpenID Connect login
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the absolute URL of the callback endpoint registered with
+	// the provider
+	RedirectURL string
+	Scopes      []string
+	// SessionSecret signs session and login state cookies
+	SessionSecret string
+	SessionTTL    time.Duration
+}
+
+// Session is the content of the session cookie
+type Session struct {
+	Subject string `json:"sub"`
+	Name    string `json:"name,omitempty"`
+	Email   string `json:"email,omitempty"`
+}
+
+// loginState is the content of the state cookie set when a login starts
+type loginState struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	Redirect string `json:"redirect"`
+}
+
+// OIDC runs the authorization code flow with PKCE against an OpenID Connect
+// provider and issues session cookies for verified users
+type OIDC struct {
+	config   Config
+	oauth2   oauth2.Config
+	verifier *oidc.IDTokenVerifier
+	signer   *signer
+}
+
+// NewOIDC discovers the provider's endpoints and signing keys from its issuer
+// URL
+func NewOIDC(ctx context.Context, config Config) (*OIDC, error) {
+	if config.Issuer == "" || config.ClientID == "" || config.RedirectURL == "" {
+		return nil, errors.New("issuer, client ID and redirect URL are required")
+	}
+	if len(config.SessionSecret) < MinSessionSecretLength {
+		return nil, fmt.Errorf("session secret must be at least %d characters", MinSessionSecretLength)
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{oidc.ScopeOpenID, "profile", "email"}
+	}
+	if config.SessionTTL <= 0 {
+		config.SessionTTL = 24 * time.Hour
+	}
+
+	provider, err := oidc.NewProvider(ctx, config.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("discovering %s: %w", config.Issuer, err)
+	}
+
+	return &OIDC{
+		config: config,
+		oauth2: oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			Endpoint:     provider.Endpoint(),
+			RedirectURL:  config.RedirectURL,
+			Scopes:       config.Scopes,
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: config.ClientID}),
+		signer:   &signer{key: []byte(config.SessionSecret)},
+	}, nil
+}
+
+// SessionTTL returns how long sessions last
+func (o *OIDC) SessionTTL() time.Duration {
+	return o.config.SessionTTL
+}
+
+// SecureCookies reports whether cookies should be marked Secure, which is the
+// case when the callback is served over HTTPS
+func (o *OIDC) SecureCookies() bool {
+	return strings.HasPrefix(o.config.RedirectURL, "https://")
+}
+
+// AuthCodeURL starts a login. It returns the provider URL to redirect the
+// user to and the state cookie value to set; redirect is where the user is
+// sent after logging in and must be a local path.
+func (o *OIDC) AuthCodeURL(redirect string) (string, string, error) {
+	state, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	login := loginState{
+		State:    state,
+		Nonce:    nonce,
+		Verifier: oauth2.GenerateVerifier(),
+		Redirect: SafeRedirect(redirect),
+	}
+	cookie, err := o.signer.sign(statePurpose, login, StateTTL)
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL := o.oauth2.AuthCodeURL(state,
+		oidc.Nonce(nonce),
+		oauth2.S256ChallengeOption(login.Verifier),
+	)
+	return authURL, cookie, nil
+}
+
+// Exchange completes a login: it checks the callback state against the state
+// cookie, redeems the code with the PKCE verifier and verifies the ID token.
+// It returns the session cookie value and the local path to redirect to.
+func (o *OIDC) Exchange(ctx context.Context, code, state, stateCookie string) (string, string, error) {
+	var login loginState
+	if err := o.signer.verify(statePurpose, stateCookie, &login); err != nil {
+		return "", "", fmt.Errorf("login state: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(state), []byte(login.State)) != 1 {
+		return "", "", fmt.Errorf("login state: %w", ErrInvalidToken)
+	}
+
+	token, err := o.oauth2.Exchange(ctx, code, oauth2.VerifierOption(login.Verifier))
+	if err != nil {
+		return "", "", fmt.Errorf("exchanging code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return "", "", errors.New("token response has no id_token")
+	}
+	idToken, err := o.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return "", "", fmt.Errorf("verifying ID token: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(login.Nonce)) != 1 {
+		return "", "", errors.New("ID token nonce does not match")
+	}
+
+	var claims struct {
+		Name              string `json:"name"`
+		PreferredUsername string `json:"preferred_username"`
+		Email             string `json:"email"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return "", "", fmt.Errorf("reading ID token claims: %w", err)
+	}
+
+	session := Session{
+		Subject: idToken.Subject,
+		Name:    claims.Name,
+		Email:   claims.Email,
+	}
+	if session.Name == "" {
+		session.Name = claims.PreferredUsername
+	}
+
+	cookie, err := o.signer.sign(sessionPurpose, session, o.config.SessionTTL)
+	if err != nil {
+		return "", "", err
+	}
+	return cookie, login.Redirect, nil
+}
+
+// Principal returns the logged-in user for a session cookie value
+func (o *OIDC) Principal(sessionCookie string) (*models.Principal, error) {
+	var session Session
+	if err := o.signer.verify(sessionPurpose, sessionCookie, &session); err != nil {
+		return nil, err
+	}
+
+	return &models.Principal{
+		Type:   "user",
+		ID:     session.Subject,
+		Name:   session.Name,
+		Scopes: []string{models.ScopeToolsRead, models.ScopeToolsProcess, models.ScopeToolsWrite},
+	}, nil
+}
+
+// SafeRedirect returns redirect if it is a local path and "/" otherwise, so
+// the login flow cannot be used as an open redirect
+func SafeRedirect(redirect string) string {
+	u, err := url.Parse(redirect)
+	if err != nil || u.Scheme != "" || u.Host != "" ||
+		!strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.Contains(redirect, `\`) {
+		return "/"
+	}
+	return redirect
+}
+
+// randomString returns 32 random bytes encoded as base64url
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
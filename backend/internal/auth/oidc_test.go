@@ -0,0 +1,331 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// authorization is a pending authorization code of fakeProvider
+type authorization struct {
+	clientID    string
+	redirectURI string
+	nonce       string
+	challenge   string
+	subject     string
+}
+
+// fakeProvider is a minimal OpenID Connect provider. It signs every login in
+// without asking, as the subject given in the login_hint parameter, and
+// supports only the authorization code flow with PKCE.
+type fakeProvider struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	signer jose.Signer
+
+	mu    sync.Mutex
+	codes map[string]authorization
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: jose.JSONWebKey{Key: key, KeyID: "fake"}},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &fakeProvider{key: key, signer: signer, codes: map[string]authorization{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/jwks", p.jwks)
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *fakeProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.URL,
+		"authorization_endpoint":                p.URL + "/authorize",
+		"token_endpoint":                        p.URL + "/token",
+		"jwks_uri":                              p.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (p *fakeProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key:       &p.key.PublicKey,
+		KeyID:     "fake",
+		Algorithm: string(jose.RS256),
+		Use:       "sig",
+	}}})
+}
+
+// authorize immediately approves the request and redirects back with a code
+func (p *fakeProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" || q.Get("client_id") == "" {
+		http.Error(w, "client_id and an absolute redirect_uri are required", http.StatusBadRequest)
+		return
+	}
+	if q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "only response_type=code with an S256 code_challenge is supported", http.StatusBadRequest)
+		return
+	}
+
+	code, err := randomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	p.mu.Lock()
+	p.codes[code] = authorization{
+		clientID:    q.Get("client_id"),
+		redirectURI: q.Get("redirect_uri"),
+		nonce:       q.Get("nonce"),
+		challenge:   q.Get("code_challenge"),
+		subject:     q.Get("login_hint"),
+	}
+	p.mu.Unlock()
+
+	values := redirectURI.Query()
+	values.Set("code", code)
+	values.Set("state", q.Get("state"))
+	redirectURI.RawQuery = values.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+// token redeems an authorization code, once, for a signed ID token
+func (p *fakeProvider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	auth, ok := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+
+	clientID := r.PostForm.Get("client_id")
+	if user, _, hasBasic := r.BasicAuth(); hasBasic {
+		clientID = user
+	}
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || clientID != auth.clientID || r.PostForm.Get("redirect_uri") != auth.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != auth.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   p.URL,
+		"sub":   auth.subject,
+		"aud":   auth.clientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": auth.nonce,
+		"name":  "Test User " + auth.subject,
+		"email": auth.subject + "@example.com",
+	})
+	signed, err := p.signer.Sign(claims)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	idToken, _ := signed.CompactSerialize()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": "access-" + code,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func newTestOIDC(t *testing.T, provider *fakeProvider) *OIDC {
+	t.Helper()
+
+	o, err := NewOIDC(context.Background(), Config{
+		Issuer:        provider.URL,
+		ClientID:      "web-tools",
+		RedirectURL:   "http://tools.test/api/v1/auth/callback",
+		SessionSecret: "test-session-secret-0123456789abcdef",
+	})
+	if err != nil {
+		t.Fatalf("NewOIDC: %v", err)
+	}
+	return o
+}
+
+// authorize sends the user to authURL as subject and returns the code and
+// state the provider redirects back with
+func authorize(t *testing.T, authURL, subject string) (string, string) {
+	t.Helper()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL + "&login_hint=" + url.QueryEscape(subject))
+	if err != nil {
+		t.Fatalf("authorizing: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d, want %d", resp.StatusCode, http.StatusFound)
+	}
+
+	callback, err := resp.Location()
+	if err != nil {
+		t.Fatalf("authorize redirect: %v", err)
+	}
+	if got := callback.Scheme + "://" + callback.Host + callback.Path; got != "http://tools.test/api/v1/auth/callback" {
+		t.Fatalf("redirected to %s, want the callback", got)
+	}
+	return callback.Query().Get("code"), callback.Query().Get("state")
+}
+
+func TestLoginFlow(t *testing.T) {
+	provider := newFakeProvider(t)
+	o := newTestOIDC(t, provider)
+	ctx := context.Background()
+
+	authURL, stateCookie, err := o.AuthCodeURL("/tools/json")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, state := authorize(t, authURL, "alice")
+
+	sessionCookie, redirect, err := o.Exchange(ctx, code, state, stateCookie)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if redirect != "/tools/json" {
+		t.Errorf("redirect = %q, want /tools/json", redirect)
+	}
+
+	principal, err := o.Principal(sessionCookie)
+	if err != nil {
+		t.Fatalf("Principal: %v", err)
+	}
+	if principal.Type != "user" || principal.ID != "alice" || principal.Name != "Test User alice" {
+		t.Errorf("principal = %+v, want user alice", principal)
+	}
+
+	// Codes are single use
+	if _, _, err := o.Exchange(ctx, code, state, stateCookie); err == nil {
+		t.Error("Exchange accepted a redeemed code")
+	}
+}
+
+func TestLoginRejectsMismatchedState(t *testing.T) {
+	provider := newFakeProvider(t)
+	o := newTestOIDC(t, provider)
+	ctx := context.Background()
+
+	authURL, _, err := o.AuthCodeURL("/")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, state := authorize(t, authURL, "alice")
+
+	// The state cookie of another login in the same browser
+	_, otherCookie, err := o.AuthCodeURL("/")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	if _, _, err := o.Exchange(ctx, code, state, otherCookie); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Exchange with another login's state = %v, want ErrInvalidToken", err)
+	}
+
+	if _, _, err := o.Exchange(ctx, code, state, ""); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Exchange without a state cookie = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestLoginRejectsOtherSessionSecret(t *testing.T) {
+	provider := newFakeProvider(t)
+	o := newTestOIDC(t, provider)
+	other, err := NewOIDC(context.Background(), Config{
+		Issuer:        provider.URL,
+		ClientID:      "web-tools",
+		RedirectURL:   "http://tools.test/api/v1/auth/callback",
+		SessionSecret: "another-session-secret-0123456789abc",
+	})
+	if err != nil {
+		t.Fatalf("NewOIDC: %v", err)
+	}
+
+	authURL, stateCookie, err := o.AuthCodeURL("/")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, state := authorize(t, authURL, "alice")
+	sessionCookie, _, err := o.Exchange(context.Background(), code, state, stateCookie)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	if _, err := other.Principal(sessionCookie); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Principal with another secret = %v, want ErrInvalidToken", err)
+	}
+	if _, err := o.Principal("forged.signature"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Principal of a forged cookie = %v, want ErrInvalidToken", err)
+	}
+	// A state cookie is signed for another purpose and is not a session
+	if _, err := o.Principal(stateCookie); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Principal of a state cookie = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestSafeRedirect(t *testing.T) {
+	tests := map[string]string{
+		"/tools/json?x=1":       "/tools/json?x=1",
+		"":                      "/",
+		"tools":                 "/",
+		"//evil.example":        "/",
+		"/\\evil.example":       "/",
+		"https://evil.example/": "/",
+	}
+	for redirect, want := range tests {
+		if got := SafeRedirect(redirect); got != want {
+			t.Errorf("SafeRedirect(%q) = %q, want %q", redirect, got, want)
+		}
+	}
+}
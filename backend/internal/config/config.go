@@ -0,0 +1,437 @@
+// Package config loads the server configuration from defaults, an optional
+// YAML or TOML file, a .env file and the environment, in increasing order of
+// precedence, and validates it before anything starts.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the complete server configuration. Each value is read from the
+// file key named by its yaml tag (nested by section) and from the
+// environment variables in its env tag, the first set one winning.
+type Config struct {
+	Env         string   `yaml:"env" env:"ENV"`
+	Port        int      `yaml:"port" env:"PORT"`
+	LogLevel    string   `yaml:"log_level" env:"LOG_LEVEL"`
+	CORSOrigins []string `yaml:"cors_origins" env:"CORS_ORIGINS,CORS_ORIGIN"`
+
+	Server    Server    `yaml:"server"`
+	API       API       `yaml:"api"`
+	Tools     Tools     `yaml:"tools"`
+	Database  Database  `yaml:"database"`
+	RateLimit RateLimit `yaml:"rate_limit"`
+	Auth      Auth      `yaml:"auth"`
+	Metrics   Metrics   `yaml:"metrics"`
+	Tracing   Tracing   `yaml:"tracing"`
+}
+
+// Server holds the HTTP server timeouts and the reverse proxies trusted to
+// report client addresses
+type Server struct {
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	// TrustedProxies lists the addresses or CIDR ranges whose
+	// X-Forwarded-For headers are believed; by default none are, and the
+	// client address is the address of the connection
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+}
+
+// API configures the unversioned /api alias of /api/v1, kept for clients
+// written before the API was versioned
+type API struct {
+	// Legacy serves the alias; its responses carry deprecation headers
+	Legacy bool `yaml:"legacy" env:"API_LEGACY"`
+	// LegacySunset is the YYYY-MM-DD date announced for removing the alias,
+	// none when empty
+	LegacySunset string `yaml:"legacy_sunset" env:"API_LEGACY_SUNSET"`
+	// LegacyToolErrors answers invalid tool input with 200 and the error in
+	// the response body, and unknown tools with 500, as before typed errors
+	LegacyToolErrors bool `yaml:"legacy_tool_errors" env:"API_LEGACY_TOOL_ERRORS"`
+}
+
+// Tools bounds the work of a single tool run, and of all the steps of a
+// pipeline or recipe together
+type Tools struct {
+	MaxInputBytes   int           `yaml:"max_input_bytes" env:"TOOL_MAX_INPUT_BYTES"`
+	Timeout         time.Duration `yaml:"timeout" env:"TOOL_TIMEOUT"`
+	PipelineTimeout time.Duration `yaml:"pipeline_timeout" env:"TOOL_PIPELINE_TIMEOUT"`
+}
+
+// Database drivers
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Database selects the storage backend. An empty driver is inferred from
+// the URL, and an empty SQLite path from a sqlite:// URL.
+type Database struct {
+	Driver string `yaml:"driver" env:"DB_DRIVER"`
+	Path   string `yaml:"path" env:"DB_PATH"`
+	URL    string `yaml:"url" env:"DATABASE_URL" secret:"url"`
+	// AutoMigrate applies pending migrations on startup. Otherwise the
+	// server refuses to start until "server migrate up" has applied them,
+	// so that a "server migrate down" is not silently undone.
+	AutoMigrate bool `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+}
+
+// RateLimit configures request rate limiting. Limits use <requests>/<period>
+// and a default of "off" disables limiting. AuthFailures limits failed
+// authentications per client IP on its own and is disabled by "off".
+type RateLimit struct {
+	Default      string `yaml:"default" env:"RATE_LIMIT"`
+	Process      string `yaml:"process" env:"RATE_LIMIT_PROCESS"`
+	AuthFailures string `yaml:"auth_failures" env:"RATE_LIMIT_AUTH_FAILURES"`
+	Store        string `yaml:"store" env:"RATE_LIMIT_STORE"`
+}
+
+// Auth configures authentication
+type Auth struct {
+	// Required removes the anonymous scopes, so every API request needs a
+	// login session or an API key
+	Required bool `yaml:"required" env:"AUTH_REQUIRED"`
+	OIDC     OIDC `yaml:"oidc"`
+}
+
+// OIDC configures OpenID Connect login, which is enabled when Issuer is set
+type OIDC struct {
+	Issuer        string        `yaml:"issuer" env:"OIDC_ISSUER"`
+	ClientID      string        `yaml:"client_id" env:"OIDC_CLIENT_ID"`
+	ClientSecret  string        `yaml:"client_secret" env:"OIDC_CLIENT_SECRET" secret:"true"`
+	RedirectURL   string        `yaml:"redirect_url" env:"OIDC_REDIRECT_URL"`
+	Scopes        []string      `yaml:"scopes" env:"OIDC_SCOPES"`
+	SessionSecret string        `yaml:"session_secret" env:"SESSION_SECRET" secret:"true"`
+	SessionTTL    time.Duration `yaml:"session_ttl" env:"SESSION_TTL"`
+}
+
+// Metrics configures the Prometheus endpoint, which needs no authentication
+type Metrics struct {
+	// Enabled serves /metrics; metrics are collected either way
+	Enabled bool `yaml:"enabled" env:"METRICS_ENABLED"`
+	// Listen serves /metrics on its own address, such as 127.0.0.1:9090,
+	// instead of next to the API
+	Listen string `yaml:"listen" env:"METRICS_LISTEN"`
+}
+
+// Tracing configures OpenTelemetry trace export. The exporter is "none",
+// "otlp" (OTLP over HTTP to Endpoint), "stdout" or "file" (JSON lines
+// written to File).
+type Tracing struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER"`
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	File        string  `yaml:"file" env:"TRACING_FILE"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// Default returns the configuration used when nothing is set
+func Default() *Config {
+	return &Config{
+		Env:         "development",
+		Port:        8080,
+		LogLevel:    "info",
+		CORSOrigins: []string{"http://localhost:5173", "http://localhost:5174"},
+		Server: Server{
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		API: API{
+			Legacy:       true,
+			LegacySunset: "2027-06-30",
+		},
+		Tools: Tools{
+			MaxInputBytes:   1 << 20,
+			Timeout:         10 * time.Second,
+			PipelineTimeout: 30 * time.Second,
+		},
+		RateLimit: RateLimit{
+			Default:      "300/1m",
+			Process:      "60/1m",
+			AuthFailures: "10/1m",
+			Store:        "memory",
+		},
+		Auth: Auth{
+			OIDC: OIDC{
+				Scopes:     []string{"openid", "profile", "email"},
+				SessionTTL: 24 * time.Hour,
+			},
+		},
+		Tracing: Tracing{
+			Exporter:    "none",
+			Endpoint:    "http://localhost:4318",
+			ServiceName: "web-tools-backend",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the YAML or TOML file at
+// path (CONFIG_FILE when empty, skipped when both are), a .env file in the
+// working directory and the environment. All invalid values are reported
+// together.
+func Load(path string) (*Config, error) {
+	config := Default()
+
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf(".env: %w", err)
+	}
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+
+	var problems []string
+	if path != "" {
+		fileProblems, err := config.loadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, fileProblems...)
+	}
+	problems = append(problems, config.loadEnv()...)
+
+	config.Database.resolve()
+	problems = append(problems, config.validate()...)
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return config, nil
+}
+
+// Sunset returns the removal date of the /api alias, zero when none is set
+func (a API) Sunset() (time.Time, error) {
+	if a.LegacySunset == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", a.LegacySunset)
+}
+
+// DataSource returns the SQLite path or PostgreSQL URL to open
+func (d Database) DataSource() string {
+	if d.Driver == DriverPostgres {
+		return d.URL
+	}
+	return d.Path
+}
+
+// resolve fills in the driver and SQLite path implied by the URL
+func (d *Database) resolve() {
+	switch d.Driver {
+	case "":
+		if strings.HasPrefix(d.URL, "postgres://") || strings.HasPrefix(d.URL, "postgresql://") {
+			d.Driver = DriverPostgres
+		} else {
+			d.Driver = DriverSQLite
+		}
+	case "postgresql", "pgx":
+		d.Driver = DriverPostgres
+	}
+
+	if d.Driver == DriverSQLite && d.Path == "" {
+		d.Path = strings.TrimPrefix(d.URL, "sqlite://")
+		if d.Path == "" {
+			d.Path = "./data/web-tools.db"
+		}
+	}
+}
+
+// loadFile applies the values in a YAML or TOML file, chosen by extension.
+// It fails if the file cannot be read or parsed and returns a problem for
+// every unknown key or invalid value.
+func (c *Config) loadFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+
+	raw := map[string]interface{}{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported extension %q, use .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	values := map[string]interface{}{}
+	flatten(raw, "", values)
+
+	var problems []string
+	for _, f := range c.fields() {
+		value, ok := values[f.key]
+		if !ok {
+			continue
+		}
+		delete(values, f.key)
+		if err := f.setRaw(value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s: %v", path, f.key, err))
+		}
+	}
+
+	unknown := make([]string, 0, len(values))
+	for key := range values {
+		unknown = append(unknown, key)
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		problems = append(problems, fmt.Sprintf("%s: unknown key %s", path, key))
+	}
+
+	return problems, nil
+}
+
+// loadEnv applies the environment variables that are set
+func (c *Config) loadEnv() []string {
+	var problems []string
+	for _, f := range c.fields() {
+		for _, name := range f.env {
+			value, ok := os.LookupEnv(name)
+			if !ok || value == "" {
+				continue
+			}
+			if err := f.set(value); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+			}
+			break
+		}
+	}
+	return problems
+}
+
+// field is a single configuration value with where it is read from
+type field struct {
+	// key is the dotted file key, such as server.read_timeout
+	key    string
+	env    []string
+	secret string
+	value  reflect.Value
+}
+
+// name identifies the field in messages, such as "port (PORT)"
+func (f field) name() string {
+	return fmt.Sprintf("%s (%s)", f.key, f.env[0])
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// fields lists the configuration values in declaration order
+func (c *Config) fields() []field {
+	var fields []field
+	collectFields(reflect.ValueOf(c).Elem(), "", &fields)
+	return fields
+}
+
+func collectFields(v reflect.Value, prefix string, fields *[]field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key := prefix + sf.Tag.Get("yaml")
+		if sf.Type.Kind() == reflect.Struct {
+			collectFields(v.Field(i), key+".", fields)
+			continue
+		}
+		*fields = append(*fields, field{
+			key:    key,
+			env:    strings.Split(sf.Tag.Get("env"), ","),
+			secret: sf.Tag.Get("secret"),
+			value:  v.Field(i),
+		})
+	}
+}
+
+// setRaw sets the field from a decoded file value
+func (f field) setRaw(raw interface{}) error {
+	switch raw := raw.(type) {
+	case []interface{}:
+		if f.value.Kind() != reflect.Slice {
+			return errors.New("must not be a list")
+		}
+		list := make([]string, 0, len(raw))
+		for _, item := range raw {
+			list = append(list, fmt.Sprint(item))
+		}
+		f.value.Set(reflect.ValueOf(list))
+		return nil
+	default:
+		return f.set(fmt.Sprint(raw))
+	}
+}
+
+// set parses value into the field. Lists are separated by commas or spaces.
+func (f field) set(value string) error {
+	switch {
+	case f.value.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, use a value such as 30s", value)
+		}
+		f.value.SetInt(int64(d))
+	case f.value.Kind() == reflect.String:
+		f.value.SetString(value)
+	case f.value.Kind() == reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		f.value.SetInt(int64(n))
+	case f.value.Kind() == reflect.Float64:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		f.value.SetFloat(n)
+	case f.value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q, use true or false", value)
+		}
+		f.value.SetBool(b)
+	case f.value.Kind() == reflect.Slice:
+		f.value.Set(reflect.ValueOf(splitList(value)))
+	default:
+		return fmt.Errorf("unsupported type %s", f.value.Type())
+	}
+	return nil
+}
+
+func splitList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// flatten turns nested sections into dotted keys
+func flatten(raw map[string]interface{}, prefix string, out map[string]interface{}) {
+	for key, value := range raw {
+		if section, ok := value.(map[string]interface{}); ok {
+			flatten(section, prefix+key+".", out)
+			continue
+		}
+		out[prefix+key] = value
+	}
+}
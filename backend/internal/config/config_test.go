@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// isolate runs the test in an empty directory, so no .env is found, with
+// the named variables unset and restored afterwards, including those a .env
+// file sets
+func isolate(t *testing.T, names ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	for _, name := range append(names, "CONFIG_FILE") {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	return dir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	dir := isolate(t, "PORT", "LOG_LEVEL", "RATE_LIMIT_PROCESS", "TOOL_TIMEOUT", "CORS_ORIGINS", "CORS_ORIGIN")
+
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+port: 9000
+log_level: debug
+tools:
+  timeout: 5s
+rate_limit:
+  process: 5/1s
+`)
+	writeFile(t, filepath.Join(dir, ".env"), "PORT=9100\nLOG_LEVEL=warn\nCORS_ORIGIN=https://tools.example.com\n")
+	t.Setenv("PORT", "9200")
+	t.Setenv("CONFIG_FILE", filepath.Join(dir, "config.yaml"))
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"port from the environment over .env and the file", cfg.Port, 9200},
+		{"log level from .env over the file", cfg.LogLevel, "warn"},
+		{"CORS origins from the fallback variable", strings.Join(cfg.CORSOrigins, ","), "https://tools.example.com"},
+		{"timeout from the file", cfg.Tools.Timeout, 5 * time.Second},
+		{"process limit from the file", cfg.RateLimit.Process, "5/1s"},
+		{"default limit from the defaults", cfg.RateLimit.Default, "300/1m"},
+		{"SQLite path from the defaults", cfg.Database.Path, "./data/web-tools.db"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// An explicit path wins over CONFIG_FILE
+	writeFile(t, filepath.Join(dir, "config.toml"), "log_level = \"error\"\n[tools]\ntimeout = \"7s\"\n")
+	os.Unsetenv("LOG_LEVEL")
+	cfg, err = Load(filepath.Join(dir, "config.toml"))
+	if err != nil {
+		t.Fatalf("Load of a TOML file: %v", err)
+	}
+	if cfg.Tools.Timeout != 7*time.Second || cfg.RateLimit.Process != "60/1m" {
+		t.Errorf("TOML file: timeout %v, process limit %s; want 7s and the default", cfg.Tools.Timeout, cfg.RateLimit.Process)
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	dir := isolate(t, "PORT", "RATE_LIMIT_PROCESS", "DB_DRIVER")
+
+	writeFile(t, filepath.Join(dir, "config.yaml"), "port: 70000\nunknown: 1\ndatabase:\n  driver: mysql\n")
+	t.Setenv("RATE_LIMIT_PROCESS", "fast")
+
+	_, err := Load(filepath.Join(dir, "config.yaml"))
+	if err == nil {
+		t.Fatal("Load succeeded, want the invalid values reported")
+	}
+	for _, want := range []string{
+		"unknown key unknown",
+		"port (PORT): must be between 1 and 65535, got 70000",
+		`database.driver (DB_DRIVER): must be sqlite or postgres, got "mysql"`,
+		"rate_limit.process (RATE_LIMIT_PROCESS): invalid rate limit",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not report %q", err, want)
+		}
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Limit
+		ok    bool
+	}{
+		{"60/1m", Limit{60, time.Minute}, true},
+		{" 10/1s ", Limit{10, time.Second}, true},
+		{"100/m", Limit{100, time.Minute}, true},
+		{"5/h", Limit{5, time.Hour}, true},
+		{"3/90s", Limit{3, 90 * time.Second}, true},
+		{"60", Limit{}, false},
+		{"0/1m", Limit{}, false},
+		{"-1/1m", Limit{}, false},
+		{"x/1m", Limit{}, false},
+		{"60/", Limit{}, false},
+		{"60/0s", Limit{}, false},
+		{"60/fortnight", Limit{}, false},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.input)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseLimit(%q) = %v, %v; want %v, ok %v", tt.input, got, err, tt.want, tt.ok)
+		}
+	}
+}
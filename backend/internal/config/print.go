@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces secret values in printed configuration
+const redacted = "[redacted]"
+
+// Print writes the effective configuration as YAML, usable as a config file,
+// with each value annotated with its environment variable. Secrets are
+// redacted, and passwords removed from URLs.
+func (c *Config) Print(w io.Writer) error {
+	root, err := section(reflect.ValueOf(c).Elem())
+	if err != nil {
+		return err
+	}
+	root.HeadComment = "Effective configuration, secrets redacted"
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// section builds the YAML mapping for a struct of configuration values
+func section(v reflect.Value) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: sf.Tag.Get("yaml")}
+
+		var value *yaml.Node
+		if sf.Type.Kind() == reflect.Struct {
+			var err error
+			if value, err = section(v.Field(i)); err != nil {
+				return nil, err
+			}
+		} else {
+			value = &yaml.Node{}
+			if err := value.Encode(redact(v.Field(i).Interface(), sf.Tag.Get("secret"))); err != nil {
+				return nil, fmt.Errorf("%s: %w", sf.Name, err)
+			}
+			if value.Kind == yaml.SequenceNode {
+				value.Style = yaml.FlowStyle
+			}
+			value.LineComment = strings.ReplaceAll(sf.Tag.Get("env"), ",", " or ")
+		}
+
+		node.Content = append(node.Content, key, value)
+	}
+
+	return node, nil
+}
+
+// redact hides value according to its secret tag
+func redact(value interface{}, secret string) interface{} {
+	s, ok := value.(string)
+	if !ok || s == "" {
+		return value
+	}
+
+	switch secret {
+	case "true":
+		return redacted
+	case "url":
+		// Key/value connection strings cannot be redacted selectively
+		u, err := url.Parse(s)
+		if err != nil || u.Scheme == "" {
+			return redacted
+		}
+		if query := u.Query(); query.Has("password") {
+			query.Set("password", "xxxxx")
+			u.RawQuery = query.Encode()
+		}
+		return u.Redacted()
+	default:
+		return value
+	}
+}
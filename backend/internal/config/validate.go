@@ -0,0 +1,199 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	validEnvs      = []string{"development", "test", "staging", "production"}
+	validLogLevels = []string{"debug", "info", "warn", "error"}
+	validStores    = []string{"memory", "database"}
+	validExporters = []string{"none", "otlp", "stdout", "file"}
+)
+
+// minSessionSecretLength is the shortest session secret login accepts
+const minSessionSecretLength = 32
+
+// validate returns a problem for every invalid value
+func (c *Config) validate() []string {
+	var problems []string
+	problem := func(key, format string, args ...interface{}) {
+		problems = append(problems, c.field(key).name()+": "+fmt.Sprintf(format, args...))
+	}
+
+	if !contains(validEnvs, c.Env) {
+		problem("env", "must be one of %s, got %q", strings.Join(validEnvs, ", "), c.Env)
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		problem("port", "must be between 1 and 65535, got %d", c.Port)
+	}
+	if !contains(validLogLevels, c.LogLevel) {
+		problem("log_level", "must be one of %s, got %q", strings.Join(validLogLevels, ", "), c.LogLevel)
+	}
+	if len(c.CORSOrigins) == 0 {
+		problem("cors_origins", "at least one origin is required")
+	}
+	for _, origin := range c.CORSOrigins {
+		if err := validateOrigin(origin); err != nil {
+			problem("cors_origins", "%q %v", origin, err)
+		}
+	}
+
+	timeouts := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"server.read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+		{"tools.timeout", c.Tools.Timeout},
+		{"tools.pipeline_timeout", c.Tools.PipelineTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value <= 0 {
+			problem(timeout.key, "must be positive")
+		}
+	}
+
+	for _, proxy := range c.Server.TrustedProxies {
+		if !validProxy(proxy) {
+			problem("server.trusted_proxies", "%q must be an IP address or CIDR range", proxy)
+		}
+	}
+
+	// Responses cut off by the write timeout never reach the client
+	if c.Tools.PipelineTimeout >= c.Server.WriteTimeout {
+		problem("tools.pipeline_timeout", "must be shorter than server.write_timeout (%s)", c.Server.WriteTimeout)
+	}
+	if c.Tools.MaxInputBytes <= 0 {
+		problem("tools.max_input_bytes", "must be positive")
+	}
+	if _, err := c.API.Sunset(); err != nil {
+		problem("api.legacy_sunset", "must be a YYYY-MM-DD date, got %q", c.API.LegacySunset)
+	}
+
+	switch c.Database.Driver {
+	case DriverSQLite:
+	case DriverPostgres:
+		if c.Database.URL == "" {
+			problem("database.url", "is required for the %s driver", DriverPostgres)
+		}
+	default:
+		problem("database.driver", "must be %s or %s, got %q", DriverSQLite, DriverPostgres, c.Database.Driver)
+	}
+
+	if c.RateLimit.Default != "off" {
+		if _, err := ParseLimit(c.RateLimit.Default); err != nil {
+			problem("rate_limit.default", "%v", err)
+		}
+		if _, err := ParseLimit(c.RateLimit.Process); err != nil {
+			problem("rate_limit.process", "%v", err)
+		}
+	}
+	if c.RateLimit.AuthFailures != "off" {
+		if _, err := ParseLimit(c.RateLimit.AuthFailures); err != nil {
+			problem("rate_limit.auth_failures", "%v", err)
+		}
+	}
+	if !contains(validStores, c.RateLimit.Store) {
+		problem("rate_limit.store", "must be one of %s, got %q", strings.Join(validStores, ", "), c.RateLimit.Store)
+	}
+
+	if oidc := c.Auth.OIDC; oidc.Issuer != "" {
+		if !absoluteURL(oidc.Issuer) {
+			problem("auth.oidc.issuer", "must be an absolute URL, got %q", oidc.Issuer)
+		}
+		if oidc.ClientID == "" {
+			problem("auth.oidc.client_id", "is required when login is enabled")
+		}
+		if !absoluteURL(oidc.RedirectURL) {
+			problem("auth.oidc.redirect_url", "must be the absolute URL of /api/v1/auth/callback when login is enabled")
+		}
+		if len(oidc.SessionSecret) < minSessionSecretLength {
+			problem("auth.oidc.session_secret", "must be at least %d characters when login is enabled", minSessionSecretLength)
+		}
+		if oidc.SessionTTL <= 0 {
+			problem("auth.oidc.session_ttl", "must be positive")
+		}
+	}
+
+	if c.Metrics.Listen != "" {
+		if _, _, err := net.SplitHostPort(c.Metrics.Listen); err != nil {
+			problem("metrics.listen", "must be an address such as 127.0.0.1:9090, got %q", c.Metrics.Listen)
+		}
+	}
+
+	switch tracing := c.Tracing; tracing.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		if !absoluteURL(tracing.Endpoint) {
+			problem("tracing.endpoint", "must be an absolute URL such as http://localhost:4318, got %q", tracing.Endpoint)
+		}
+	case "file":
+		if tracing.File == "" {
+			problem("tracing.file", "is required for the file exporter")
+		}
+	default:
+		problem("tracing.exporter", "must be one of %s, got %q", strings.Join(validExporters, ", "), tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		problem("tracing.sample_ratio", "must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+	}
+
+	return problems
+}
+
+// validateOrigin checks that origin is a scheme and host without a path.
+// Wildcards are rejected because the API allows credentialed requests.
+func validateOrigin(origin string) error {
+	if origin == "*" {
+		return fmt.Errorf("is not allowed with credentials, list the origins instead")
+	}
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("must be an http or https origin such as https://tools.example.com")
+	}
+	if u.Path != "" || u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("must not have a path, query or fragment")
+	}
+	return nil
+}
+
+// validProxy reports whether value is an IP address or CIDR range, the forms
+// gin accepts as trusted proxies
+func validProxy(value string) bool {
+	if _, _, err := net.ParseCIDR(value); err == nil {
+		return true
+	}
+	return net.ParseIP(value) != nil
+}
+
+func absoluteURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// field returns the field with the given file key
+func (c *Config) field(key string) field {
+	for _, f := range c.fields() {
+		if f.key == key {
+			return f
+		}
+	}
+	panic("config: unknown key " + key)
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+const apiKeyColumns = `id, name, prefix, scopes, created_at, last_used_at, revoked_at`
+
+// CreateAPIKey stores a new API key under the SHA-256 hash of its secret
+func (db *DB) CreateAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error {
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return err
+	}
+
+	return db.queryRow(ctx, `
+		INSERT INTO api_keys (id, name, prefix, key_hash, scopes)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING created_at
+	`, key.ID, key.Name, key.Prefix, keyHash, string(scopes)).Scan(&key.CreatedAt)
+}
+
+// GetAPIKeys returns all API keys, revoked ones included, newest first
+func (db *DB) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	rows, err := db.query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY created_at DESC, name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+// GetAPIKeyByHash returns the API key whose secret has the given hash
+func (db *DB) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	key, err := scanAPIKey(db.queryRow(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = ?`, keyHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return key, err
+}
+
+// RevokeAPIKey marks the API key with the given ID as revoked. Revoking a
+// key twice keeps the original revocation time.
+func (db *DB) RevokeAPIKey(ctx context.Context, id string) error {
+	result, err := db.exec(ctx, `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP) WHERE id = ?
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// TouchAPIKey records that the API key with the given ID was just used
+func (db *DB) TouchAPIKey(ctx context.Context, id string) error {
+	_, err := db.exec(ctx, `UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP WHERE id = ?`, id)
+	return err
+}
+
+// scanAPIKey reads an api_keys row selected with apiKeyColumns
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	var key models.APIKey
+	var scopes string
+	var lastUsedAt, revokedAt sql.NullTime
+
+	if err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&scopes,
+		&key.CreatedAt,
+		&lastUsedAt,
+		&revokedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(scopes), &key.Scopes); err != nil {
+		return nil, fmt.Errorf("invalid scopes for API key %s: %w", key.ID, err)
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+
+	return &key, nil
+}
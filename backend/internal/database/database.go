@@ -1,175 +1,126 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
 	"os"
 	"path/filepath"
 
-	_ "modernc.org/sqlite"
+	"github.com/XSAM/otelsql"
+	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	_ "modernc.org/sqlite"
 )
 
 // DB represents the database connection
 type DB struct {
 	*sql.DB
+	dialect dialect
 }
 
-// Initialize creates and initializes the database
-func Initialize() (*DB, error) {
-	dbPath := os.Getenv("DB_PATH")
-	if dbPath == "" {
-		dbPath = "./data/web-tools.db"
+// Initialize opens the database and applies any pending migrations
+func Initialize(driver, dataSource string) (*DB, error) {
+	db, err := Open(driver, dataSource)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create data directory if it doesn't exist
-	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+	// Bring the schema up to date
+	if err := db.Migrate(context.Background()); err != nil {
+		db.Close()
 		return nil, err
 	}
 
-	// Open database connection
-	sqlDB, err := sql.Open("sqlite", dbPath)
-	if err != nil {
-		return nil, err
+	logrus.WithField("driver", db.Driver()).Info("Database initialized successfully")
+	return db, nil
+}
+
+// Open connects to the database without touching the schema. driver is
+// "sqlite", with a file path as dataSource, or "postgres", with a connection
+// URL.
+func Open(driver, dataSource string) (*DB, error) {
+	switch driver {
+	case DriverSQLite:
+		return openSQLite(dataSource)
+	case DriverPostgres:
+		if dataSource == "" {
+			return nil, fmt.Errorf("a connection URL is required for the %s driver", driver)
+		}
+		return openPostgres(dataSource)
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", driver)
 	}
+}
 
-	// Test connection
-	if err := sqlDB.Ping(); err != nil {
+// Driver returns the name of the database driver in use
+func (db *DB) Driver() string {
+	return db.dialect.driver()
+}
+
+func openSQLite(dbPath string) (*DB, error) {
+	// Create data directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
 		return nil, err
 	}
 
-	db := &DB{sqlDB}
+	return openDB("sqlite", dbPath, sqliteDialect{}, semconv.DBSystemSqlite)
+}
 
-	// Initialize tables
-	if err := db.initializeTables(); err != nil {
+func openPostgres(databaseURL string) (*DB, error) {
+	db, err := openDB("pgx", databaseURL, postgresDialect{}, semconv.DBSystemPostgreSQL)
+	if err != nil {
 		return nil, err
 	}
 
-	logrus.Info("Database initialized successfully")
+	db.SetMaxOpenConns(25)
+	db.SetMaxIdleConns(5)
 	return db, nil
 }
 
-// initializeTables creates the necessary tables
-func (db *DB) initializeTables() error {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS tools (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL,
-			description TEXT,
-			category TEXT NOT NULL,
-			icon TEXT,
-			features TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS user_settings (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			user_id TEXT UNIQUE,
-			settings TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS tool_history (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			tool_id TEXT NOT NULL,
-			input TEXT,
-			output TEXT,
-			settings TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-	}
-
-	for _, query := range queries {
-		if _, err := db.Exec(query); err != nil {
-			return err
-		}
+func openDB(driverName, dataSource string, d dialect, system attribute.KeyValue) (*DB, error) {
+	// Open database connection, tracing each statement as a child span of
+	// the request that ran it. Statements outside a trace, such as startup
+	// migrations, are not traced.
+	sqlDB, err := otelsql.Open(driverName, dataSource,
+		otelsql.WithAttributes(system),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableErrSkip:       true,
+			OmitConnResetSession: true,
+			OmitRows:             true,
+			SpanFilter: func(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+				return trace.SpanContextFromContext(ctx).IsValid()
+			},
+		}),
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	// Insert default tools if not exists
-	if err := db.insertDefaultTools(); err != nil {
-		return err
+	// Test connection
+	if err := sqlDB.Ping(); err != nil {
+		sqlDB.Close()
+		return nil, err
 	}
 
-	return nil
+	return &DB{DB: sqlDB, dialect: d}, nil
 }
 
-// insertDefaultTools inserts the default tools into the database
-func (db *DB) insertDefaultTools() error {
-	tools := []struct {
-		id          string
-		name        string
-		description string
-		category    string
-		icon        string
-		features    string
-	}{
-		{
-			id:          "base64",
-			name:        "Base64 Encoder/Decoder",
-			description: "Encode and decode Base64 strings",
-			category:    "encoding",
-			icon:        "code",
-			features:    `["input-validation", "output-formatting"]`,
-		},
-		{
-			id:          "json",
-			name:        "JSON Formatter/Validator",
-			description: "Format and validate JSON data",
-			category:    "formatting",
-			icon:        "braces",
-			features:    `["input-validation", "output-formatting", "settings"]`,
-		},
-		{
-			id:          "protobuf",
-			name:        "Protobuf Debug String Formatter",
-			description: "Format protobuf debug strings",
-			category:    "protocol",
-			icon:        "settings",
-			features:    `["input-validation", "output-formatting"]`,
-		},
-		{
-			id:          "unicode",
-			name:        "Unicode Encoder/Decoder",
-			description: "Encode and decode Unicode characters",
-			category:    "encoding",
-			icon:        "globe",
-			features:    `["input-validation", "output-formatting"]`,
-		},
-		{
-			id:          "url",
-			name:        "URL Encoder/Decoder",
-			description: "Encode and decode URL parameters",
-			category:    "encoding",
-			icon:        "link",
-			features:    `["input-validation", "output-formatting"]`,
-		},
-		{
-			id:          "html",
-			name:        "HTML Encoder/Decoder",
-			description: "Encode and decode HTML entities",
-			category:    "encoding",
-			icon:        "code2",
-			features:    `["input-validation", "output-formatting"]`,
-		},
-	}
-
-	for _, tool := range tools {
-		// Check if tool already exists
-		var exists bool
-		err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM tools WHERE id = ?)", tool.id).Scan(&exists)
-		if err != nil {
-			return err
-		}
+// exec runs a statement written with ? placeholders
+func (db *DB) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return db.ExecContext(ctx, db.dialect.rebind(query), args...)
+}
 
-		if !exists {
-			_, err := db.Exec(`
-				INSERT INTO tools (id, name, description, category, icon, features)
-				VALUES (?, ?, ?, ?, ?, ?)
-			`, tool.id, tool.name, tool.description, tool.category, tool.icon, tool.features)
-			if err != nil {
-				return err
-			}
-		}
-	}
+// query runs a query written with ? placeholders
+func (db *DB) query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return db.QueryContext(ctx, db.dialect.rebind(query), args...)
+}
 
-	return nil
+// queryRow runs a single-row query written with ? placeholders
+func (db *DB) queryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return db.QueryRowContext(ctx, db.dialect.rebind(query), args...)
 }
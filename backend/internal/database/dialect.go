@@ -0,0 +1,157 @@
+package database
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported values of DB_DRIVER
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// sqliteTimeFormat matches the format SQLite uses for CURRENT_TIMESTAMP
+const sqliteTimeFormat = "2006-01-02 15:04:05"
+
+// dialect captures the SQL differences between the supported databases.
+// Queries are written with SQLite-style ? placeholders and rebound per
+// dialect before they are run.
+type dialect interface {
+	// driver returns the DB_DRIVER name of the dialect
+	driver() string
+	// rebind rewrites ? placeholders into the dialect's placeholder syntax
+	rebind(query string) string
+	// timeArg converts a time into a value comparable with stored timestamps
+	timeArg(t time.Time) interface{}
+	// migrationSteps returns the statements of a migration for the dialect
+	migrationSteps(migration Migration) MigrationSteps
+	// searchQuery builds the full-text search clauses for the given words
+	searchQuery(words []string) searchQuery
+	// forUpdate returns the clause that locks selected rows until the end of
+	// the transaction
+	forUpdate() string
+}
+
+// searchQuery holds the dialect specific parts of a history search. Columns
+// must select the rank followed by the input and output snippets; higher
+// ranks are better matches.
+type searchQuery struct {
+	from    string
+	match   string
+	args    []interface{}
+	columns string
+	orderBy string
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) driver() string { return DriverSQLite }
+
+func (sqliteDialect) rebind(query string) string { return query }
+
+// timeArg formats times the way SQLite stores CURRENT_TIMESTAMP, so bound
+// times compare correctly against stored timestamps
+func (sqliteDialect) timeArg(t time.Time) interface{} {
+	return t.UTC().Format(sqliteTimeFormat)
+}
+
+func (sqliteDialect) migrationSteps(migration Migration) MigrationSteps {
+	return migration.SQLite
+}
+
+func (sqliteDialect) searchQuery(words []string) searchQuery {
+	return searchQuery{
+		from:  ` FROM tool_history_fts JOIN tool_history ON tool_history.id = tool_history_fts.rowid`,
+		match: `tool_history_fts MATCH ?`,
+		args:  []interface{}{ftsQuery(words)},
+		// bm25 scores better matches lower, so negate it
+		columns: `-bm25(tool_history_fts),
+			snippet(tool_history_fts, 0, '<mark>', '</mark>', '…', 16),
+			snippet(tool_history_fts, 1, '<mark>', '</mark>', '…', 16)`,
+		orderBy: `bm25(tool_history_fts), tool_history.id DESC`,
+	}
+}
+
+// forUpdate is empty since SQLite locks the whole database for writing
+// transactions
+func (sqliteDialect) forUpdate() string { return "" }
+
+type postgresDialect struct{}
+
+func (postgresDialect) driver() string { return DriverPostgres }
+
+// rebind numbers placeholders as $1, $2, ... skipping quoted literals
+func (postgresDialect) rebind(query string) string {
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+
+	n := 0
+	inQuote := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			inQuote = !inQuote
+		case c == '?' && !inQuote:
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+func (postgresDialect) timeArg(t time.Time) interface{} {
+	return t.UTC()
+}
+
+func (postgresDialect) migrationSteps(migration Migration) MigrationSteps {
+	return migration.Postgres
+}
+
+func (postgresDialect) searchQuery(words []string) searchQuery {
+	const headline = `'StartSel=<mark>, StopSel=</mark>, MaxWords=16, MinWords=4, MaxFragments=1, FragmentDelimiter=…'`
+
+	return searchQuery{
+		from:  ` FROM tool_history, to_tsquery('simple', ?) AS query`,
+		match: `tool_history.search_vector @@ query`,
+		args:  []interface{}{tsQuery(words)},
+		columns: `ts_rank(tool_history.search_vector, query),
+			ts_headline('simple', COALESCE(tool_history.input, ''), query, ` + headline + `),
+			ts_headline('simple', COALESCE(tool_history.output, ''), query, ` + headline + `)`,
+		orderBy: `ts_rank(tool_history.search_vector, query) DESC, tool_history.id DESC`,
+	}
+}
+
+func (postgresDialect) forUpdate() string { return " FOR UPDATE" }
+
+// ftsQuery turns words into an FTS5 query by quoting every word, so that
+// characters such as '-', ':' or '"' in pasted data are never parsed as FTS5
+// operators. The last word also matches as a prefix.
+func ftsQuery(words []string) string {
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+	}
+	terms[len(terms)-1] += "*"
+
+	return strings.Join(terms, " ")
+}
+
+// tsQuery is the PostgreSQL equivalent of ftsQuery, quoting every word as a
+// tsquery lexeme and joining them with &
+func tsQuery(words []string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `''`)
+
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = `'` + replacer.Replace(word) + `'`
+	}
+	terms[len(terms)-1] += ":*"
+
+	return strings.Join(terms, " & ")
+}
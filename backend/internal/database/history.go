@@ -0,0 +1,253 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+const historyColumns = `id, tool_id, input, output, settings, created_at`
+
+var (
+	// ErrEmptySearch is returned when a history search is given no words
+	ErrEmptySearch = errors.New("search has no words")
+	// ErrNoHistoryUser is returned when a history filter names no user, so
+	// that a missing user never selects the history of everyone
+	ErrNoHistoryUser = errors.New("history filter has no user")
+)
+
+// HistoryFilter narrows down tool history queries
+type HistoryFilter struct {
+	// UserID restricts entries to those recorded for one user or client and
+	// is required
+	UserID string
+	ToolID string
+	// From is inclusive, To is exclusive; zero values are ignored
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Offset int
+}
+
+// historyWhere builds the WHERE clause and arguments for a filter. It fails
+// with ErrNoHistoryUser rather than leave out the user condition.
+func (db *DB) historyWhere(f HistoryFilter) (string, []interface{}, error) {
+	if f.UserID == "" {
+		return "", nil, ErrNoHistoryUser
+	}
+	conditions := []string{"user_id = ?"}
+	args := []interface{}{f.UserID}
+
+	if f.ToolID != "" {
+		conditions = append(conditions, "tool_id = ?")
+		args = append(args, f.ToolID)
+	}
+	if !f.From.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, db.dialect.timeArg(f.From))
+	}
+	if !f.To.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, db.dialect.timeArg(f.To))
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+// CreateHistoryEntry records a tool invocation
+func (db *DB) CreateHistoryEntry(ctx context.Context, entry *models.ToolHistoryEntry) error {
+	settings, err := json.Marshal(entry.Settings)
+	if err != nil {
+		return err
+	}
+
+	return db.queryRow(ctx, `
+		INSERT INTO tool_history (user_id, tool_id, input, output, settings)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id, created_at
+	`, entry.UserID, entry.ToolID, entry.Input, entry.Output, string(settings)).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+// GetHistory returns a page of history entries, newest first, along with the
+// total number of entries matching the filter
+func (db *DB) GetHistory(ctx context.Context, filter HistoryFilter) ([]models.ToolHistoryEntry, int, error) {
+	where, args, err := db.historyWhere(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := db.queryRow(ctx, `SELECT COUNT(*) FROM tool_history`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + historyColumns + ` FROM tool_history` + where + ` ORDER BY created_at DESC, id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	rows, err := db.query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []models.ToolHistoryEntry{}
+	for rows.Next() {
+		entry, err := scanHistoryEntry(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, *entry)
+	}
+
+	return entries, total, rows.Err()
+}
+
+// GetHistoryEntry returns the history entry with the given ID recorded for
+// userID
+func (db *DB) GetHistoryEntry(ctx context.Context, userID string, id int) (*models.ToolHistoryEntry, error) {
+	entry, err := scanHistoryEntry(db.queryRow(ctx, `SELECT `+historyColumns+` FROM tool_history WHERE id = ? AND user_id = ?`, id, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return entry, err
+}
+
+// DeleteHistoryEntry removes the history entry with the given ID recorded for
+// userID
+func (db *DB) DeleteHistoryEntry(ctx context.Context, userID string, id int) error {
+	result, err := db.exec(ctx, `DELETE FROM tool_history WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ClearHistory removes all history entries matching the filter and returns
+// the number of entries deleted
+func (db *DB) ClearHistory(ctx context.Context, filter HistoryFilter) (int64, error) {
+	where, args, err := db.historyWhere(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.exec(ctx, `DELETE FROM tool_history`+where, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// SearchHistory runs a full-text query against the input and output of
+// history entries, best matches first. Every word must match and the last
+// word also matches as a prefix; the filter's ToolID, From, To, Limit and
+// Offset are applied as well.
+func (db *DB) SearchHistory(ctx context.Context, words []string, filter HistoryFilter) ([]models.HistorySearchResult, int, error) {
+	if len(words) == 0 {
+		return nil, 0, ErrEmptySearch
+	}
+
+	where, filterArgs, err := db.historyWhere(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	search := db.dialect.searchQuery(words)
+	from := search.from + ` WHERE ` + search.match + ` AND ` + strings.TrimPrefix(where, " WHERE ")
+	args := append(search.args, filterArgs...)
+
+	var total int
+	if err := db.queryRow(ctx, `SELECT COUNT(*)`+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	sqlQuery := `SELECT tool_history.id, tool_history.tool_id, tool_history.input, tool_history.output,
+			tool_history.settings, tool_history.created_at, ` + search.columns +
+		from + ` ORDER BY ` + search.orderBy
+	if filter.Limit > 0 {
+		sqlQuery += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	rows, err := db.query(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	results := []models.HistorySearchResult{}
+	for rows.Next() {
+		var result models.HistorySearchResult
+		var input, output, settings sql.NullString
+
+		if err := rows.Scan(
+			&result.ID,
+			&result.ToolID,
+			&input,
+			&output,
+			&settings,
+			&result.CreatedAt,
+			&result.Rank,
+			&result.InputSnippet,
+			&result.OutputSnippet,
+		); err != nil {
+			return nil, 0, err
+		}
+
+		result.Input = input.String
+		result.Output = output.String
+		if settings.Valid && settings.String != "" {
+			if err := json.Unmarshal([]byte(settings.String), &result.Settings); err != nil {
+				return nil, 0, fmt.Errorf("invalid settings for history entry %d: %w", result.ID, err)
+			}
+		}
+		results = append(results, result)
+	}
+
+	return results, total, rows.Err()
+}
+
+// scanHistoryEntry reads a tool_history row selected with historyColumns
+func scanHistoryEntry(row rowScanner) (*models.ToolHistoryEntry, error) {
+	var entry models.ToolHistoryEntry
+	var input, output, settings sql.NullString
+
+	if err := row.Scan(
+		&entry.ID,
+		&entry.ToolID,
+		&input,
+		&output,
+		&settings,
+		&entry.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	entry.Input = input.String
+	entry.Output = output.String
+	if settings.Valid && settings.String != "" {
+		if err := json.Unmarshal([]byte(settings.String), &entry.Settings); err != nil {
+			return nil, fmt.Errorf("invalid settings for history entry %d: %w", entry.ID, err)
+		}
+	}
+
+	return &entry, nil
+}
@@ -0,0 +1,492 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Migration is a single versioned schema change. Each database has its own
+// statements for the same version so both stay at the same schema.
+type Migration struct {
+	Version     int
+	Description string
+	SQLite      MigrationSteps
+	Postgres    MigrationSteps
+}
+
+// MigrationSteps holds the statements of a migration for one database. Up
+// and Down are applied in a transaction, statement by statement.
+type MigrationSteps struct {
+	Up   []string
+	Down []string
+}
+
+// MigrationStatus reports whether a migration has been applied
+type MigrationStatus struct {
+	Version     int
+	Description string
+	Applied     bool
+	AppliedAt   time.Time
+}
+
+// migrations lists every schema change in order. Versions must be unique and
+// increasing; never edit a migration once it has been released, add a new one
+// (TestReleasedMigrationsUnchanged checks this).
+// The first migrations use IF NOT EXISTS so that databases created before
+// migrations existed are adopted without changes.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "create tools, user_settings and tool_history",
+		SQLite: MigrationSteps{
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS tools (
+					id TEXT PRIMARY KEY,
+					name TEXT NOT NULL,
+					description TEXT,
+					category TEXT NOT NULL,
+					icon TEXT,
+					features TEXT,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				)`,
+				`CREATE TABLE IF NOT EXISTS user_settings (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					user_id TEXT UNIQUE,
+					settings TEXT,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				)`,
+				`CREATE TABLE IF NOT EXISTS tool_history (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					tool_id TEXT NOT NULL,
+					input TEXT,
+					output TEXT,
+					settings TEXT,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				)`,
+			},
+			Down: []string{
+				`DROP TABLE IF EXISTS tool_history`,
+				`DROP TABLE IF EXISTS user_settings`,
+				`DROP TABLE IF EXISTS tools`,
+			},
+		},
+		Postgres: MigrationSteps{
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS tools (
+					id TEXT PRIMARY KEY,
+					name TEXT NOT NULL,
+					description TEXT,
+					category TEXT NOT NULL,
+					icon TEXT,
+					features TEXT,
+					created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+				)`,
+				`CREATE TABLE IF NOT EXISTS user_settings (
+					id BIGSERIAL PRIMARY KEY,
+					user_id TEXT UNIQUE,
+					settings TEXT,
+					created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+				)`,
+				`CREATE TABLE IF NOT EXISTS tool_history (
+					id BIGSERIAL PRIMARY KEY,
+					tool_id TEXT NOT NULL,
+					input TEXT,
+					output TEXT,
+					settings TEXT,
+					created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+				)`,
+			},
+			Down: []string{
+				`DROP TABLE IF EXISTS tool_history`,
+				`DROP TABLE IF EXISTS user_settings`,
+				`DROP TABLE IF EXISTS tools`,
+			},
+		},
+	},
+	{
+		Version:     2,
+		Description: "create proto_schemas",
+		SQLite: MigrationSteps{
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS proto_schemas (
+					id TEXT PRIMARY KEY,
+					name TEXT NOT NULL,
+					kind TEXT NOT NULL,
+					descriptor BLOB NOT NULL,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				)`,
+			},
+			Down: []string{
+				`DROP TABLE IF EXISTS proto_schemas`,
+			},
+		},
+		Postgres: MigrationSteps{
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS proto_schemas (
+					id TEXT PRIMARY KEY,
+					name TEXT NOT NULL,
+					kind TEXT NOT NULL,
+					descriptor BYTEA NOT NULL,
+					created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+				)`,
+			},
+			Down: []string{
+				`DROP TABLE IF EXISTS proto_schemas`,
+			},
+		},
+	},
+	{
+		Version:     3,
+		Description: "create recipes",
+		SQLite: MigrationSteps{
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS recipes (
+					id TEXT PRIMARY KEY,
+					name TEXT NOT NULL,
+					description TEXT,
+					steps TEXT NOT NULL,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				)`,
+			},
+			Down: []string{
+				`DROP TABLE IF EXISTS recipes`,
+			},
+		},
+		Postgres: MigrationSteps{
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS recipes (
+					id TEXT PRIMARY KEY,
+					name TEXT NOT NULL,
+					description TEXT,
+					steps TEXT NOT NULL,
+					created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+				)`,
+			},
+			Down: []string{
+				`DROP TABLE IF EXISTS recipes`,
+			},
+		},
+	},
+	{
+		Version:     4,
+		Description: "add full-text search over tool_history",
+		SQLite: MigrationSteps{
+			Up: []string{
+				`CREATE VIRTUAL TABLE IF NOT EXISTS tool_history_fts USING fts5(
+					input,
+					output,
+					content = 'tool_history',
+					content_rowid = 'id'
+				)`,
+				`CREATE TRIGGER IF NOT EXISTS tool_history_fts_insert AFTER INSERT ON tool_history BEGIN
+					INSERT INTO tool_history_fts (rowid, input, output) VALUES (new.id, new.input, new.output);
+				END`,
+				`CREATE TRIGGER IF NOT EXISTS tool_history_fts_delete AFTER DELETE ON tool_history BEGIN
+					INSERT INTO tool_history_fts (tool_history_fts, rowid, input, output) VALUES ('delete', old.id, old.input, old.output);
+				END`,
+				`CREATE TRIGGER IF NOT EXISTS tool_history_fts_update AFTER UPDATE ON tool_history BEGIN
+					INSERT INTO tool_history_fts (tool_history_fts, rowid, input, output) VALUES ('delete', old.id, old.input, old.output);
+					INSERT INTO tool_history_fts (rowid, input, output) VALUES (new.id, new.input, new.output);
+				END`,
+				// Index rows recorded before the search table existed
+				`INSERT INTO tool_history_fts (tool_history_fts) VALUES ('rebuild')`,
+			},
+			Down: []string{
+				`DROP TRIGGER IF EXISTS tool_history_fts_update`,
+				`DROP TRIGGER IF EXISTS tool_history_fts_delete`,
+				`DROP TRIGGER IF EXISTS tool_history_fts_insert`,
+				`DROP TABLE IF EXISTS tool_history_fts`,
+			},
+		},
+		Postgres: MigrationSteps{
+			Up: []string{
+				// A generated column keeps the search vector in sync, existing
+				// rows included
+				`ALTER TABLE tool_history ADD COLUMN IF NOT EXISTS search_vector tsvector
+					GENERATED ALWAYS AS (to_tsvector('simple', COALESCE(input, '') || ' ' || COALESCE(output, ''))) STORED`,
+				`CREATE INDEX IF NOT EXISTS tool_history_search_idx ON tool_history USING GIN (search_vector)`,
+			},
+			Down: []string{
+				`DROP INDEX IF EXISTS tool_history_search_idx`,
+				`ALTER TABLE tool_history DROP COLUMN IF EXISTS search_vector`,
+			},
+		},
+	},
+	{
+		Version:     5,
+		Description: "create rate_limits",
+		SQLite: MigrationSteps{
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS rate_limits (
+					key TEXT PRIMARY KEY,
+					tokens REAL NOT NULL,
+					updated_at INTEGER NOT NULL
+				)`,
+			},
+			Down: []string{
+				`DROP TABLE IF EXISTS rate_limits`,
+			},
+		},
+		Postgres: MigrationSteps{
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS rate_limits (
+					key TEXT PRIMARY KEY,
+					tokens DOUBLE PRECISION NOT NULL,
+					updated_at BIGINT NOT NULL
+				)`,
+			},
+			Down: []string{
+				`DROP TABLE IF EXISTS rate_limits`,
+			},
+		},
+	},
+	{
+		Version:     6,
+		Description: "create api_keys",
+		SQLite: MigrationSteps{
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS api_keys (
+					id TEXT PRIMARY KEY,
+					name TEXT NOT NULL,
+					prefix TEXT NOT NULL,
+					key_hash TEXT NOT NULL UNIQUE,
+					scopes TEXT NOT NULL,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					last_used_at TIMESTAMP,
+					revoked_at TIMESTAMP
+				)`,
+			},
+			Down: []string{
+				`DROP TABLE IF EXISTS api_keys`,
+			},
+		},
+		Postgres: MigrationSteps{
+			Up: []string{
+				`CREATE TABLE IF NOT EXISTS api_keys (
+					id TEXT PRIMARY KEY,
+					name TEXT NOT NULL,
+					prefix TEXT NOT NULL,
+					key_hash TEXT NOT NULL UNIQUE,
+					scopes TEXT NOT NULL,
+					created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+					last_used_at TIMESTAMPTZ,
+					revoked_at TIMESTAMPTZ
+				)`,
+			},
+			Down: []string{
+				`DROP TABLE IF EXISTS api_keys`,
+			},
+		},
+	},
+	{
+		Version:     7,
+		Description: "add user_id to tool_history",
+		SQLite: MigrationSteps{
+			Up: []string{
+				`ALTER TABLE tool_history ADD COLUMN user_id TEXT`,
+				`CREATE INDEX IF NOT EXISTS tool_history_user_idx ON tool_history (user_id, created_at)`,
+			},
+			Down: []string{
+				`DROP INDEX IF EXISTS tool_history_user_idx`,
+				`ALTER TABLE tool_history DROP COLUMN user_id`,
+			},
+		},
+		Postgres: MigrationSteps{
+			Up: []string{
+				`ALTER TABLE tool_history ADD COLUMN IF NOT EXISTS user_id TEXT`,
+				`CREATE INDEX IF NOT EXISTS tool_history_user_idx ON tool_history (user_id, created_at)`,
+			},
+			Down: []string{
+				`DROP INDEX IF EXISTS tool_history_user_idx`,
+				`ALTER TABLE tool_history DROP COLUMN IF EXISTS user_id`,
+			},
+		},
+	},
+}
+
+// Migrate applies all pending migrations in order
+func (db *DB) Migrate(ctx context.Context) error {
+	applied, err := db.appliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if err := db.applyMigration(ctx, migration, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rollback reverts the most recently applied migrations, up to steps of them
+func (db *DB) Rollback(ctx context.Context, steps int) error {
+	applied, err := db.appliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+		migration := migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if err := db.applyMigration(ctx, migration, false); err != nil {
+			return err
+		}
+		steps--
+	}
+
+	return nil
+}
+
+// MigrationStatus returns every known migration and whether it is applied
+func (db *DB) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := db.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		appliedAt, ok := applied[migration.Version]
+		statuses = append(statuses, MigrationStatus{
+			Version:     migration.Version,
+			Description: migration.Description,
+			Applied:     ok,
+			AppliedAt:   appliedAt,
+		})
+	}
+
+	return statuses, nil
+}
+
+// PendingMigrations returns the versions of known migrations that have not
+// been applied. Unlike MigrationStatus it never changes the schema, so it is
+// safe to call from health checks.
+func (db *DB) PendingMigrations(ctx context.Context) ([]int, error) {
+	rows, err := db.query(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]bool{}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var pending []int
+	for _, migration := range migrations {
+		if !applied[migration.Version] {
+			pending = append(pending, migration.Version)
+		}
+	}
+	return pending, nil
+}
+
+// appliedMigrations returns the applied migration versions and when they
+// were applied, creating the schema_migrations table if needed
+func (db *DB) appliedMigrations(ctx context.Context) (map[int]time.Time, error) {
+	_, err := db.exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		description TEXT,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.query(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// applyMigration runs the up or down statements of a migration and records
+// the result in schema_migrations, all in one transaction
+func (db *DB) applyMigration(ctx context.Context, migration Migration, up bool) (err error) {
+	steps := db.dialect.migrationSteps(migration)
+	direction, statements := "up", steps.Up
+	if !up {
+		direction, statements = "down", steps.Down
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("migration %d (%s) %s: %w", migration.Version, migration.Description, direction, err)
+		}
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, db.dialect.rebind(`INSERT INTO schema_migrations (version, description) VALUES (?, ?)`),
+			migration.Version, migration.Description)
+	} else {
+		_, err = tx.ExecContext(ctx, db.dialect.rebind(`DELETE FROM schema_migrations WHERE version = ?`), migration.Version)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"version":     migration.Version,
+		"description": migration.Description,
+		"direction":   direction,
+	}).Info("Migration applied")
+	return nil
+}
+
+// LatestMigration returns the version of the newest known migration
+func LatestMigration() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// UpdateRateLimitBucket loads the token bucket stored under key, passes it to
+// update and saves the result, all in one transaction so that instances
+// sharing the database never lose updates. A missing bucket is passed as zero
+// tokens and a zero time.
+func (db *DB) UpdateRateLimitBucket(ctx context.Context, key string, update func(tokens float64, updatedAt time.Time) (float64, time.Time)) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Inserting first takes the write lock before the bucket is read
+	_, err = tx.ExecContext(ctx, db.dialect.rebind(`
+		INSERT INTO rate_limits (key, tokens, updated_at) VALUES (?, 0, 0)
+		ON CONFLICT (key) DO NOTHING
+	`), key)
+	if err != nil {
+		return err
+	}
+
+	var tokens float64
+	var updatedNanos int64
+	err = tx.QueryRowContext(ctx, db.dialect.rebind(`SELECT tokens, updated_at FROM rate_limits WHERE key = ?`+db.dialect.forUpdate()), key).
+		Scan(&tokens, &updatedNanos)
+	if err != nil {
+		return err
+	}
+
+	var updatedAt time.Time
+	if updatedNanos != 0 {
+		updatedAt = time.Unix(0, updatedNanos)
+	}
+
+	tokens, updatedAt = update(tokens, updatedAt)
+
+	_, err = tx.ExecContext(ctx, db.dialect.rebind(`UPDATE rate_limits SET tokens = ?, updated_at = ? WHERE key = ?`),
+		tokens, updatedAt.UnixNano(), key)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetRateLimitBucket returns the token bucket stored under key without
+// changing it. A missing bucket is returned as zero tokens and a zero time.
+func (db *DB) GetRateLimitBucket(ctx context.Context, key string) (float64, time.Time, error) {
+	var tokens float64
+	var updatedNanos int64
+	err := db.queryRow(ctx, `SELECT tokens, updated_at FROM rate_limits WHERE key = ?`, key).Scan(&tokens, &updatedNanos)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && updatedNanos == 0) {
+		return 0, time.Time{}, nil
+	}
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	return tokens, time.Unix(0, updatedNanos), nil
+}
+
+// PruneRateLimitBuckets removes buckets last updated before the given time
+// and returns the number removed
+func (db *DB) PruneRateLimitBuckets(ctx context.Context, before time.Time) (int64, error) {
+	result, err := db.exec(ctx, `DELETE FROM rate_limits WHERE updated_at < ?`, before.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+const recipeColumns = `id, name, description, steps, created_at, updated_at`
+
+// CreateRecipe stores a new recipe. It returns ErrConflict if the ID is
+// already taken.
+func (db *DB) CreateRecipe(ctx context.Context, recipe *models.Recipe) error {
+	steps, err := json.Marshal(recipe.Steps)
+	if err != nil {
+		return err
+	}
+
+	var exists bool
+	if err := db.queryRow(ctx, "SELECT EXISTS(SELECT 1 FROM recipes WHERE id = ?)", recipe.ID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return ErrConflict
+	}
+
+	_, err = db.exec(ctx, `
+		INSERT INTO recipes (id, name, description, steps)
+		VALUES (?, ?, ?, ?)
+	`, recipe.ID, recipe.Name, recipe.Description, string(steps))
+	if err != nil {
+		return err
+	}
+
+	return db.queryRow(ctx, `SELECT created_at, updated_at FROM recipes WHERE id = ?`, recipe.ID).
+		Scan(&recipe.CreatedAt, &recipe.UpdatedAt)
+}
+
+// GetRecipes returns all recipes ordered by name
+func (db *DB) GetRecipes(ctx context.Context) ([]models.Recipe, error) {
+	rows, err := db.query(ctx, `SELECT `+recipeColumns+` FROM recipes ORDER BY name, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipes := []models.Recipe{}
+	for rows.Next() {
+		recipe, err := scanRecipe(rows)
+		if err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, *recipe)
+	}
+
+	return recipes, rows.Err()
+}
+
+// GetRecipe returns the recipe with the given ID
+func (db *DB) GetRecipe(ctx context.Context, id string) (*models.Recipe, error) {
+	recipe, err := scanRecipe(db.queryRow(ctx, `SELECT `+recipeColumns+` FROM recipes WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return recipe, err
+}
+
+// UpdateRecipe replaces the name, description and steps of a recipe
+func (db *DB) UpdateRecipe(ctx context.Context, recipe *models.Recipe) error {
+	steps, err := json.Marshal(recipe.Steps)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.exec(ctx, `
+		UPDATE recipes
+		SET name = ?, description = ?, steps = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, recipe.Name, recipe.Description, string(steps), recipe.ID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return db.queryRow(ctx, `SELECT created_at, updated_at FROM recipes WHERE id = ?`, recipe.ID).
+		Scan(&recipe.CreatedAt, &recipe.UpdatedAt)
+}
+
+// DeleteRecipe removes the recipe with the given ID
+func (db *DB) DeleteRecipe(ctx context.Context, id string) error {
+	result, err := db.exec(ctx, `DELETE FROM recipes WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// scanRecipe reads a recipes row selected with recipeColumns
+func scanRecipe(row rowScanner) (*models.Recipe, error) {
+	var recipe models.Recipe
+	var description sql.NullString
+	var steps string
+
+	if err := row.Scan(
+		&recipe.ID,
+		&recipe.Name,
+		&description,
+		&steps,
+		&recipe.CreatedAt,
+		&recipe.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	recipe.Description = description.String
+	if err := json.Unmarshal([]byte(steps), &recipe.Steps); err != nil {
+		return nil, fmt.Errorf("invalid steps for recipe %s: %w", recipe.ID, err)
+	}
+
+	return &recipe, nil
+}
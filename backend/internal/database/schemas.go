@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+const schemaColumns = `id, name, kind, descriptor, created_at`
+
+// CreateSchema stores a compiled protobuf schema
+func (db *DB) CreateSchema(ctx context.Context, schema *models.ProtoSchema) error {
+	_, err := db.exec(ctx, `
+		INSERT INTO proto_schemas (id, name, kind, descriptor)
+		VALUES (?, ?, ?, ?)
+	`, schema.ID, schema.Name, schema.Kind, schema.Descriptor)
+	if err != nil {
+		return err
+	}
+
+	return db.queryRow(ctx, `SELECT created_at FROM proto_schemas WHERE id = ?`, schema.ID).Scan(&schema.CreatedAt)
+}
+
+// GetSchemas returns all stored schemas, newest first
+func (db *DB) GetSchemas(ctx context.Context) ([]models.ProtoSchema, error) {
+	rows, err := db.query(ctx, `SELECT `+schemaColumns+` FROM proto_schemas ORDER BY created_at DESC, name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schemas := []models.ProtoSchema{}
+	for rows.Next() {
+		var schema models.ProtoSchema
+		if err := rows.Scan(&schema.ID, &schema.Name, &schema.Kind, &schema.Descriptor, &schema.CreatedAt); err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, rows.Err()
+}
+
+// GetSchema returns the schema with the given ID
+func (db *DB) GetSchema(ctx context.Context, id string) (*models.ProtoSchema, error) {
+	var schema models.ProtoSchema
+	err := db.queryRow(ctx, `SELECT `+schemaColumns+` FROM proto_schemas WHERE id = ?`, id).
+		Scan(&schema.ID, &schema.Name, &schema.Kind, &schema.Descriptor, &schema.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema, nil
+}
+
+// DeleteSchema removes the schema with the given ID
+func (db *DB) DeleteSchema(ctx context.Context, id string) error {
+	result, err := db.exec(ctx, `DELETE FROM proto_schemas WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+// GetUserSettings returns the stored settings for a user or client. It
+// returns ErrNotFound if nothing has been saved yet.
+func (db *DB) GetUserSettings(ctx context.Context, userID string) (*models.UserSettings, error) {
+	var userSettings models.UserSettings
+	var settings sql.NullString
+
+	err := db.queryRow(ctx, `
+		SELECT id, user_id, settings, created_at, updated_at
+		FROM user_settings WHERE user_id = ?
+	`, userID).Scan(
+		&userSettings.ID,
+		&userSettings.UserID,
+		&settings,
+		&userSettings.CreatedAt,
+		&userSettings.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	userSettings.Settings = map[string]interface{}{}
+	if settings.Valid && settings.String != "" {
+		if err := json.Unmarshal([]byte(settings.String), &userSettings.Settings); err != nil {
+			return nil, fmt.Errorf("invalid settings for user %s: %w", userID, err)
+		}
+	}
+
+	return &userSettings, nil
+}
+
+// SaveUserSettings creates or replaces the stored settings for a user or client
+func (db *DB) SaveUserSettings(ctx context.Context, userID string, settings map[string]interface{}) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.exec(ctx, `
+		INSERT INTO user_settings (user_id, settings)
+		VALUES (?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET settings = excluded.settings, updated_at = CURRENT_TIMESTAMP
+	`, userID, string(data))
+	return err
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+// Store is the storage used by the service layer and the HTTP handlers. *DB
+// implements it on top of SQLite and PostgreSQL.
+type Store interface {
+	// Health checks
+	PingContext(ctx context.Context) error
+	Driver() string
+	PendingMigrations(ctx context.Context) ([]int, error)
+
+	GetTools(ctx context.Context) ([]models.Tool, error)
+	GetTool(ctx context.Context, id string) (*models.Tool, error)
+	SyncTools(ctx context.Context, tools []models.Tool) error
+
+	CreateSchema(ctx context.Context, schema *models.ProtoSchema) error
+	GetSchemas(ctx context.Context) ([]models.ProtoSchema, error)
+	GetSchema(ctx context.Context, id string) (*models.ProtoSchema, error)
+	DeleteSchema(ctx context.Context, id string) error
+
+	CreateRecipe(ctx context.Context, recipe *models.Recipe) error
+	GetRecipes(ctx context.Context) ([]models.Recipe, error)
+	GetRecipe(ctx context.Context, id string) (*models.Recipe, error)
+	UpdateRecipe(ctx context.Context, recipe *models.Recipe) error
+	DeleteRecipe(ctx context.Context, id string) error
+
+	CreateHistoryEntry(ctx context.Context, entry *models.ToolHistoryEntry) error
+	GetHistory(ctx context.Context, filter HistoryFilter) ([]models.ToolHistoryEntry, int, error)
+	GetHistoryEntry(ctx context.Context, userID string, id int) (*models.ToolHistoryEntry, error)
+	DeleteHistoryEntry(ctx context.Context, userID string, id int) error
+	ClearHistory(ctx context.Context, filter HistoryFilter) (int64, error)
+	SearchHistory(ctx context.Context, words []string, filter HistoryFilter) ([]models.HistorySearchResult, int, error)
+
+	GetUserSettings(ctx context.Context, userID string) (*models.UserSettings, error)
+	SaveUserSettings(ctx context.Context, userID string, settings map[string]interface{}) error
+
+	CreateAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error
+	GetAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	TouchAPIKey(ctx context.Context, id string) error
+}
+
+var _ Store = (*DB)(nil)
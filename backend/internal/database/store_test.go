@@ -0,0 +1,558 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+// forEachDriver runs test against a freshly migrated SQLite database and,
+// when TEST_DATABASE_URL points at a disposable PostgreSQL database, against
+// PostgreSQL. All tables of that database are dropped before and after.
+func forEachDriver(t *testing.T, test func(t *testing.T, db *DB)) {
+	t.Run(DriverSQLite, func(t *testing.T) {
+		db, err := Initialize(DriverSQLite, filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatalf("opening SQLite: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		test(t, db)
+	})
+
+	t.Run(DriverPostgres, func(t *testing.T) {
+		url := os.Getenv("TEST_DATABASE_URL")
+		if url == "" {
+			t.Skip("TEST_DATABASE_URL is not set")
+		}
+
+		ctx := context.Background()
+		db, err := Open(DriverPostgres, url)
+		if err != nil {
+			t.Fatalf("opening PostgreSQL: %v", err)
+		}
+		reset := func() error { return db.Rollback(ctx, len(migrations)) }
+		if err := reset(); err != nil {
+			t.Fatalf("resetting PostgreSQL: %v", err)
+		}
+		t.Cleanup(func() {
+			if err := reset(); err != nil {
+				t.Errorf("resetting PostgreSQL: %v", err)
+			}
+			db.Close()
+		})
+		if err := db.Migrate(ctx); err != nil {
+			t.Fatalf("migrating PostgreSQL: %v", err)
+		}
+		test(t, db)
+	})
+}
+
+func TestMigrations(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *DB) {
+		ctx := context.Background()
+
+		pending, err := db.PendingMigrations(ctx)
+		if err != nil || len(pending) > 0 {
+			t.Fatalf("PendingMigrations = %v, %v; want none", pending, err)
+		}
+
+		if err := db.Rollback(ctx, 1); err != nil {
+			t.Fatalf("Rollback: %v", err)
+		}
+		pending, err = db.PendingMigrations(ctx)
+		if err != nil || len(pending) != 1 || pending[0] != LatestMigration() {
+			t.Fatalf("PendingMigrations after rollback = %v, %v; want [%d]", pending, err, LatestMigration())
+		}
+
+		if err := db.Migrate(ctx); err != nil {
+			t.Fatalf("Migrate: %v", err)
+		}
+		if pending, _ := db.PendingMigrations(ctx); len(pending) > 0 {
+			t.Fatalf("PendingMigrations after reapplying = %v, want none", pending)
+		}
+	})
+}
+
+// releasedMigrations pins the statements of every released migration, as
+// the first 16 hex digits of their SHA-256. A released migration must never
+// change since databases that applied it would not pick up the change; add a
+// new migration instead, and its checksums here.
+var releasedMigrations = map[int]struct{ sqlite, postgres string }{
+	1: {"ac2db248a430e02e", "74eaaffca8dc092f"},
+	2: {"2ad836666e697d7f", "ef3fb8932feb138a"},
+	3: {"288a805db049bc32", "43a8ee0962c0d848"},
+	4: {"a097e32685ee64e5", "8c8d9ec4a0f6279a"},
+	5: {"4a49675ff4cee6fd", "5f78141de7b1362a"},
+	6: {"618ad26fc88d2a10", "e048187782a3dc7c"},
+	7: {"599475114c517c65", "1eae517e00ca9961"},
+}
+
+// migrationChecksum returns the first 16 hex digits of the SHA-256 of the up
+// and down statements of a migration. Runs of whitespace count as a single
+// space so that reindenting a migration does not change it.
+func migrationChecksum(steps MigrationSteps) string {
+	h := sha256.New()
+	for _, statements := range [][]string{steps.Up, steps.Down} {
+		for _, statement := range statements {
+			fmt.Fprintf(h, "%s\x00", strings.Join(strings.Fields(statement), " "))
+		}
+		h.Write([]byte{1})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+func TestReleasedMigrationsUnchanged(t *testing.T) {
+	for _, migration := range migrations {
+		released, ok := releasedMigrations[migration.Version]
+		if !ok {
+			t.Errorf("migration %d (%s) has no released checksums", migration.Version, migration.Description)
+			continue
+		}
+		if got := migrationChecksum(migration.SQLite); got != released.sqlite {
+			t.Errorf("SQLite statements of migration %d changed (checksum %s, want %s); add a new migration instead", migration.Version, got, released.sqlite)
+		}
+		if got := migrationChecksum(migration.Postgres); got != released.postgres {
+			t.Errorf("PostgreSQL statements of migration %d changed (checksum %s, want %s); add a new migration instead", migration.Version, got, released.postgres)
+		}
+	}
+}
+
+func TestTools(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *DB) {
+		ctx := context.Background()
+		catalog := []models.Tool{
+			{ID: "base64", Name: "Base64", Category: "encoding", Icon: "b", Features: []string{"encode", "decode"}},
+			{ID: "json", Name: "JSON", Category: "formatting", Icon: "j", Features: []string{"format"}},
+		}
+
+		if err := db.SyncTools(ctx, catalog); err != nil {
+			t.Fatalf("SyncTools: %v", err)
+		}
+		tools, err := db.GetTools(ctx)
+		if err != nil || len(tools) != 2 {
+			t.Fatalf("GetTools = %d tools, %v; want 2", len(tools), err)
+		}
+
+		catalog[1].Name = "JSON Formatter"
+		if err := db.SyncTools(ctx, catalog); err != nil {
+			t.Fatalf("SyncTools with changed metadata: %v", err)
+		}
+		tool, err := db.GetTool(ctx, "json")
+		if err != nil {
+			t.Fatalf("GetTool: %v", err)
+		}
+		if tool.Name != "JSON Formatter" || len(tool.Features) != 1 {
+			t.Errorf("GetTool = %+v, want the updated metadata", tool)
+		}
+
+		if _, err := db.GetTool(ctx, "nope"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetTool of an unknown tool = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestSchemas(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *DB) {
+		ctx := context.Background()
+		schema := &models.ProtoSchema{ID: "s1", Name: "people", Kind: "proto", Descriptor: []byte{0x0a, 0x00, 0xff}}
+
+		if err := db.CreateSchema(ctx, schema); err != nil {
+			t.Fatalf("CreateSchema: %v", err)
+		}
+		if schema.CreatedAt.IsZero() {
+			t.Error("CreateSchema did not set CreatedAt")
+		}
+
+		stored, err := db.GetSchema(ctx, "s1")
+		if err != nil {
+			t.Fatalf("GetSchema: %v", err)
+		}
+		if stored.Name != "people" || string(stored.Descriptor) != string(schema.Descriptor) {
+			t.Errorf("GetSchema = %+v, want %+v", stored, schema)
+		}
+
+		schemas, err := db.GetSchemas(ctx)
+		if err != nil || len(schemas) != 1 {
+			t.Fatalf("GetSchemas = %d schemas, %v; want 1", len(schemas), err)
+		}
+
+		if err := db.DeleteSchema(ctx, "s1"); err != nil {
+			t.Fatalf("DeleteSchema: %v", err)
+		}
+		if err := db.DeleteSchema(ctx, "s1"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("DeleteSchema twice = %v, want ErrNotFound", err)
+		}
+		if _, err := db.GetSchema(ctx, "s1"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetSchema after delete = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestRecipes(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *DB) {
+		ctx := context.Background()
+		recipe := &models.Recipe{
+			ID:   "r1",
+			Name: "Encode twice",
+			Steps: []models.PipelineStep{
+				{ToolID: "base64", Settings: map[string]interface{}{"mode": "encode"}},
+				{ToolID: "base64", Settings: map[string]interface{}{"mode": "encode"}},
+			},
+		}
+
+		if err := db.CreateRecipe(ctx, recipe); err != nil {
+			t.Fatalf("CreateRecipe: %v", err)
+		}
+		if err := db.CreateRecipe(ctx, recipe); !errors.Is(err, ErrConflict) {
+			t.Errorf("CreateRecipe twice = %v, want ErrConflict", err)
+		}
+
+		stored, err := db.GetRecipe(ctx, "r1")
+		if err != nil {
+			t.Fatalf("GetRecipe: %v", err)
+		}
+		if len(stored.Steps) != 2 || stored.Steps[1].Settings["mode"] != "encode" {
+			t.Errorf("GetRecipe steps = %+v, want the created steps", stored.Steps)
+		}
+
+		recipe.Name = "Renamed"
+		recipe.Steps = []models.PipelineStep{{ToolID: "json"}}
+		if err := db.UpdateRecipe(ctx, recipe); err != nil {
+			t.Fatalf("UpdateRecipe: %v", err)
+		}
+		recipes, err := db.GetRecipes(ctx)
+		if err != nil || len(recipes) != 1 || recipes[0].Name != "Renamed" || len(recipes[0].Steps) != 1 {
+			t.Fatalf("GetRecipes = %+v, %v; want the renamed recipe", recipes, err)
+		}
+
+		if err := db.UpdateRecipe(ctx, &models.Recipe{ID: "nope"}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("UpdateRecipe of an unknown recipe = %v, want ErrNotFound", err)
+		}
+		if err := db.DeleteRecipe(ctx, "r1"); err != nil {
+			t.Fatalf("DeleteRecipe: %v", err)
+		}
+		if _, err := db.GetRecipe(ctx, "r1"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetRecipe after delete = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestHistory(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *DB) {
+		ctx := context.Background()
+		entries := []*models.ToolHistoryEntry{
+			{UserID: "alice", ToolID: "base64", Input: "hello postgres", Output: "aGVsbG8gcG9zdGdyZXM=", Settings: map[string]interface{}{"mode": "encode"}},
+			{UserID: "alice", ToolID: "url", Input: "a b&c", Output: "a+b%26c"},
+			{UserID: "bob", ToolID: "url", Input: "hello bob", Output: "hello+bob"},
+		}
+		for _, entry := range entries {
+			if err := db.CreateHistoryEntry(ctx, entry); err != nil {
+				t.Fatalf("CreateHistoryEntry: %v", err)
+			}
+		}
+
+		alice := HistoryFilter{UserID: "alice"}
+		if _, total, err := db.GetHistory(ctx, alice); err != nil || total != 2 {
+			t.Errorf("GetHistory for alice = %d entries, %v; want 2", total, err)
+		}
+		if _, total, err := db.GetHistory(ctx, HistoryFilter{UserID: "alice", ToolID: "url"}); err != nil || total != 1 {
+			t.Errorf("GetHistory filtered by tool = %d entries, %v; want 1", total, err)
+		}
+		if _, total, err := db.GetHistory(ctx, HistoryFilter{UserID: "alice", From: time.Now().Add(time.Hour)}); err != nil || total != 0 {
+			t.Errorf("GetHistory from the future = %d entries, %v; want 0", total, err)
+		}
+		page, total, err := db.GetHistory(ctx, HistoryFilter{UserID: "alice", Limit: 1})
+		if err != nil || total != 2 || len(page) != 1 {
+			t.Errorf("GetHistory with a limit = %d of %d entries, %v; want 1 of 2", len(page), total, err)
+		}
+
+		results, total, err := db.SearchHistory(ctx, []string{"postg"}, alice)
+		if err != nil || total != 1 {
+			t.Fatalf("SearchHistory by prefix = %d results, %v; want 1", total, err)
+		}
+		if !strings.Contains(results[0].InputSnippet, "<mark>postgres</mark>") {
+			t.Errorf("input snippet = %q, want the match highlighted", results[0].InputSnippet)
+		}
+		if results[0].Settings["mode"] != "encode" {
+			t.Errorf("search result settings = %v, want the recorded settings", results[0].Settings)
+		}
+		if _, total, err := db.SearchHistory(ctx, []string{"hello", "nomatch"}, alice); err != nil || total != 0 {
+			t.Errorf("SearchHistory with a missing word = %d results, %v; want 0", total, err)
+		}
+		if _, total, err := db.SearchHistory(ctx, []string{"bob"}, alice); err != nil || total != 0 {
+			t.Errorf("SearchHistory across users = %d results, %v; want 0", total, err)
+		}
+
+		entry, err := db.GetHistoryEntry(ctx, "alice", entries[1].ID)
+		if err != nil || entry.ToolID != "url" {
+			t.Fatalf("GetHistoryEntry = %+v, %v; want the url entry", entry, err)
+		}
+		if _, err := db.GetHistoryEntry(ctx, "bob", entries[1].ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetHistoryEntry of another user = %v, want ErrNotFound", err)
+		}
+		if err := db.DeleteHistoryEntry(ctx, "bob", entries[1].ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("DeleteHistoryEntry of another user = %v, want ErrNotFound", err)
+		}
+		if err := db.DeleteHistoryEntry(ctx, "alice", entries[1].ID); err != nil {
+			t.Fatalf("DeleteHistoryEntry: %v", err)
+		}
+
+		if deleted, err := db.ClearHistory(ctx, alice); err != nil || deleted != 1 {
+			t.Errorf("ClearHistory = %d, %v; want 1", deleted, err)
+		}
+		if _, total, _ := db.GetHistory(ctx, HistoryFilter{UserID: "bob"}); total != 1 {
+			t.Errorf("history of bob after clearing alice = %d entries, want 1", total)
+		}
+
+		// A filter without a user is refused rather than matching everyone
+		if _, _, err := db.GetHistory(ctx, HistoryFilter{}); !errors.Is(err, ErrNoHistoryUser) {
+			t.Errorf("GetHistory without a user = %v, want ErrNoHistoryUser", err)
+		}
+		if _, _, err := db.SearchHistory(ctx, []string{"hello"}, HistoryFilter{ToolID: "url"}); !errors.Is(err, ErrNoHistoryUser) {
+			t.Errorf("SearchHistory without a user = %v, want ErrNoHistoryUser", err)
+		}
+		if deleted, err := db.ClearHistory(ctx, HistoryFilter{}); !errors.Is(err, ErrNoHistoryUser) || deleted != 0 {
+			t.Errorf("ClearHistory without a user = %d, %v; want ErrNoHistoryUser", deleted, err)
+		}
+		if _, total, _ := db.GetHistory(ctx, HistoryFilter{UserID: "bob"}); total != 1 {
+			t.Errorf("history of bob after clearing without a user = %d entries, want 1", total)
+		}
+	})
+}
+
+func TestSearchHistory(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *DB) {
+		ctx := context.Background()
+		inputs := []string{
+			`{"key": "value-one"}`,
+			`user:admin role:viewer`,
+			`say "quoted" twice`,
+			`prefixes and suffixes`,
+		}
+		for _, input := range inputs {
+			if err := db.CreateHistoryEntry(ctx, &models.ToolHistoryEntry{UserID: "alice", ToolID: "json", Input: input}); err != nil {
+				t.Fatalf("CreateHistoryEntry: %v", err)
+			}
+		}
+
+		// Characters that are FTS5 or tsquery operators are searched for
+		// literally rather than breaking the query
+		tests := []struct {
+			words []string
+			total int
+		}{
+			{[]string{"value-one"}, 1},
+			{[]string{"-one"}, 1},
+			{[]string{"user:admin"}, 1},
+			{[]string{"role:"}, 1},
+			{[]string{`"quoted"`}, 1},
+			{[]string{"OR", "NOT"}, 0},
+			{[]string{"pref"}, 1},
+			{[]string{"pref", "and"}, 0},
+			{[]string{"prefixes", "suff"}, 1},
+			{[]string{"suff", "prefixes"}, 0},
+		}
+		for _, tt := range tests {
+			if _, total, err := db.SearchHistory(ctx, tt.words, HistoryFilter{UserID: "alice"}); err != nil || total != tt.total {
+				t.Errorf("SearchHistory(%q) = %d results, %v; want %d", tt.words, total, err, tt.total)
+			}
+		}
+
+		if _, _, err := db.SearchHistory(ctx, nil, HistoryFilter{UserID: "alice"}); !errors.Is(err, ErrEmptySearch) {
+			t.Errorf("SearchHistory without words = %v, want ErrEmptySearch", err)
+		}
+	})
+}
+
+func TestHistoryPagesAndDates(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *DB) {
+		ctx := context.Background()
+		day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+		// One entry a day for five days, plus one of another user
+		var ids []int
+		for i := 0; i < 5; i++ {
+			entry := &models.ToolHistoryEntry{UserID: "alice", ToolID: "json", Input: fmt.Sprint(i)}
+			if err := db.CreateHistoryEntry(ctx, entry); err != nil {
+				t.Fatalf("CreateHistoryEntry: %v", err)
+			}
+			if _, err := db.exec(ctx, `UPDATE tool_history SET created_at = ? WHERE id = ?`,
+				db.dialect.timeArg(day.AddDate(0, 0, i)), entry.ID); err != nil {
+				t.Fatalf("dating entry %d: %v", i, err)
+			}
+			ids = append(ids, entry.ID)
+		}
+		if err := db.CreateHistoryEntry(ctx, &models.ToolHistoryEntry{UserID: "bob", ToolID: "json"}); err != nil {
+			t.Fatalf("CreateHistoryEntry: %v", err)
+		}
+
+		pageIDs := func(filter HistoryFilter) ([]int, int) {
+			t.Helper()
+			filter.UserID = "alice"
+			entries, total, err := db.GetHistory(ctx, filter)
+			if err != nil {
+				t.Fatalf("GetHistory(%+v): %v", filter, err)
+			}
+			got := []int{}
+			for _, entry := range entries {
+				got = append(got, entry.ID)
+			}
+			return got, total
+		}
+
+		tests := []struct {
+			name   string
+			filter HistoryFilter
+			want   []int
+			total  int
+		}{
+			{"first page", HistoryFilter{Limit: 2}, []int{ids[4], ids[3]}, 5},
+			{"second page", HistoryFilter{Limit: 2, Offset: 2}, []int{ids[2], ids[1]}, 5},
+			{"last page", HistoryFilter{Limit: 2, Offset: 4}, []int{ids[0]}, 5},
+			{"past the end", HistoryFilter{Limit: 2, Offset: 6}, []int{}, 5},
+			{"from is inclusive", HistoryFilter{From: day.AddDate(0, 0, 3)}, []int{ids[4], ids[3]}, 2},
+			{"to is exclusive", HistoryFilter{To: day.AddDate(0, 0, 1)}, []int{ids[0]}, 1},
+			{"between", HistoryFilter{From: day.AddDate(0, 0, 1), To: day.AddDate(0, 0, 3)}, []int{ids[2], ids[1]}, 2},
+			{"paged between", HistoryFilter{From: day.AddDate(0, 0, 1), Limit: 1, Offset: 1}, []int{ids[3]}, 4},
+		}
+		for _, tt := range tests {
+			got, total := pageIDs(tt.filter)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) || total != tt.total {
+				t.Errorf("%s: got %v of %d, want %v of %d", tt.name, got, total, tt.want, tt.total)
+			}
+		}
+
+		// Clearing a date range leaves the rest of the history alone
+		cleared, err := db.ClearHistory(ctx, HistoryFilter{UserID: "alice", To: day.AddDate(0, 0, 2)})
+		if err != nil || cleared != 2 {
+			t.Errorf("ClearHistory before day 2 = %d, %v; want 2", cleared, err)
+		}
+		if got, total := pageIDs(HistoryFilter{}); total != 3 || got[len(got)-1] != ids[2] {
+			t.Errorf("after clearing a range: %v, want the three newest entries", got)
+		}
+
+		cleared, err = db.ClearHistory(ctx, HistoryFilter{UserID: "alice"})
+		if err != nil || cleared != 3 {
+			t.Errorf("ClearHistory = %d, %v; want 3", cleared, err)
+		}
+		if _, total, _ := db.GetHistory(ctx, HistoryFilter{UserID: "bob"}); total != 1 {
+			t.Errorf("history of bob after clearing alice = %d entries, want 1", total)
+		}
+	})
+}
+
+func TestUserSettings(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *DB) {
+		ctx := context.Background()
+
+		if _, err := db.GetUserSettings(ctx, "alice"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetUserSettings before saving = %v, want ErrNotFound", err)
+		}
+
+		if err := db.SaveUserSettings(ctx, "alice", map[string]interface{}{"theme": "dark"}); err != nil {
+			t.Fatalf("SaveUserSettings: %v", err)
+		}
+		if err := db.SaveUserSettings(ctx, "alice", map[string]interface{}{"theme": "light", "autoSave": false}); err != nil {
+			t.Fatalf("SaveUserSettings again: %v", err)
+		}
+
+		settings, err := db.GetUserSettings(ctx, "alice")
+		if err != nil {
+			t.Fatalf("GetUserSettings: %v", err)
+		}
+		if settings.Settings["theme"] != "light" || settings.Settings["autoSave"] != false {
+			t.Errorf("GetUserSettings = %v, want the last saved settings", settings.Settings)
+		}
+	})
+}
+
+func TestRateLimitBuckets(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *DB) {
+		ctx := context.Background()
+		now := time.Unix(1700000000, 123456789)
+
+		tokens, updatedAt, err := db.GetRateLimitBucket(ctx, "default|ip:192.0.2.1")
+		if err != nil || tokens != 0 || !updatedAt.IsZero() {
+			t.Fatalf("GetRateLimitBucket of a missing bucket = %v, %v, %v; want zero", tokens, updatedAt, err)
+		}
+
+		// update sees the stored bucket and its result is saved
+		for i, want := range []float64{0, 4.5} {
+			err := db.UpdateRateLimitBucket(ctx, "default|ip:192.0.2.1", func(tokens float64, updatedAt time.Time) (float64, time.Time) {
+				if tokens != want {
+					t.Errorf("update %d got %v tokens, want %v", i, tokens, want)
+				}
+				return tokens + 4.5, now
+			})
+			if err != nil {
+				t.Fatalf("UpdateRateLimitBucket: %v", err)
+			}
+		}
+
+		tokens, updatedAt, err = db.GetRateLimitBucket(ctx, "default|ip:192.0.2.1")
+		if err != nil || tokens != 9 || !updatedAt.Equal(now) {
+			t.Errorf("GetRateLimitBucket = %v, %v, %v; want 9 tokens updated at %v", tokens, updatedAt, err, now)
+		}
+
+		if err := db.UpdateRateLimitBucket(ctx, "default|ip:192.0.2.2", func(float64, time.Time) (float64, time.Time) {
+			return 1, now.Add(-time.Hour)
+		}); err != nil {
+			t.Fatalf("UpdateRateLimitBucket: %v", err)
+		}
+		if pruned, err := db.PruneRateLimitBuckets(ctx, now.Add(-time.Minute)); err != nil || pruned != 1 {
+			t.Errorf("PruneRateLimitBuckets = %d, %v; want the idle bucket pruned", pruned, err)
+		}
+		if tokens, _, _ := db.GetRateLimitBucket(ctx, "default|ip:192.0.2.1"); tokens != 9 {
+			t.Errorf("active bucket has %v tokens after pruning, want 9", tokens)
+		}
+	})
+}
+
+func TestAPIKeys(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *DB) {
+		ctx := context.Background()
+		key := &models.APIKey{ID: "k1", Name: "ci", Prefix: "wt_abc", Scopes: []string{"tools:read", "tools:process"}}
+
+		if err := db.CreateAPIKey(ctx, key, "hash"); err != nil {
+			t.Fatalf("CreateAPIKey: %v", err)
+		}
+
+		stored, err := db.GetAPIKeyByHash(ctx, "hash")
+		if err != nil {
+			t.Fatalf("GetAPIKeyByHash: %v", err)
+		}
+		if stored.ID != "k1" || len(stored.Scopes) != 2 || stored.LastUsedAt != nil {
+			t.Errorf("GetAPIKeyByHash = %+v, want the created key", stored)
+		}
+
+		if err := db.TouchAPIKey(ctx, "k1"); err != nil {
+			t.Fatalf("TouchAPIKey: %v", err)
+		}
+		if err := db.RevokeAPIKey(ctx, "k1"); err != nil {
+			t.Fatalf("RevokeAPIKey: %v", err)
+		}
+		keys, err := db.GetAPIKeys(ctx)
+		if err != nil || len(keys) != 1 {
+			t.Fatalf("GetAPIKeys = %d keys, %v; want 1", len(keys), err)
+		}
+		if keys[0].LastUsedAt == nil || keys[0].RevokedAt == nil {
+			t.Errorf("GetAPIKeys = %+v, want the key used and revoked", keys[0])
+		}
+
+		if err := db.RevokeAPIKey(ctx, "nope"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("RevokeAPIKey of an unknown key = %v, want ErrNotFound", err)
+		}
+		if _, err := db.GetAPIKeyByHash(ctx, "other"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetAPIKeyByHash of an unknown hash = %v, want ErrNotFound", err)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/sirupsen/logrus"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+var (
+	// ErrNotFound is returned when a requested record does not exist
+	ErrNotFound = errors.New("record not found")
+	// ErrConflict is returned when a record with the same key already exists
+	ErrConflict = errors.New("record already exists")
+)
+
+const toolColumns = `id, name, description, category, icon, features, created_at, updated_at`
+
+// GetTools returns every tool in the catalog ordered by ID
+func (db *DB) GetTools(ctx context.Context) ([]models.Tool, error) {
+	rows, err := db.query(ctx, `SELECT `+toolColumns+` FROM tools ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tools := []models.Tool{}
+	for rows.Next() {
+		tool, err := scanTool(rows)
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, *tool)
+	}
+
+	return tools, rows.Err()
+}
+
+// GetTool returns the tool with the given ID
+func (db *DB) GetTool(ctx context.Context, id string) (*models.Tool, error) {
+	row := db.queryRow(ctx, `SELECT `+toolColumns+` FROM tools WHERE id = ?`, id)
+
+	tool, err := scanTool(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return tool, err
+}
+
+// SyncTools seeds the catalog with the given tools, inserting missing rows and
+// updating rows whose metadata has drifted from the registered processors.
+func (db *DB) SyncTools(ctx context.Context, tools []models.Tool) error {
+	for _, tool := range tools {
+		existing, err := db.GetTool(ctx, tool.ID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+
+		features, err := json.Marshal(tool.Features)
+		if err != nil {
+			return err
+		}
+
+		if existing == nil {
+			_, err := db.exec(ctx, `
+				INSERT INTO tools (id, name, description, category, icon, features)
+				VALUES (?, ?, ?, ?, ?, ?)
+			`, tool.ID, tool.Name, tool.Description, tool.Category, tool.Icon, string(features))
+			if err != nil {
+				return err
+			}
+			logrus.WithField("tool_id", tool.ID).Info("Tool added to catalog")
+			continue
+		}
+
+		if toolMetadataEqual(*existing, tool) {
+			continue
+		}
+
+		_, err = db.exec(ctx, `
+			UPDATE tools
+			SET name = ?, description = ?, category = ?, icon = ?, features = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, tool.Name, tool.Description, tool.Category, tool.Icon, string(features), tool.ID)
+		if err != nil {
+			return err
+		}
+		logrus.WithField("tool_id", tool.ID).Info("Tool catalog entry updated")
+	}
+
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTool reads a tools row selected with toolColumns
+func scanTool(row rowScanner) (*models.Tool, error) {
+	var tool models.Tool
+	var description, icon, features sql.NullString
+
+	if err := row.Scan(
+		&tool.ID,
+		&tool.Name,
+		&description,
+		&tool.Category,
+		&icon,
+		&features,
+		&tool.CreatedAt,
+		&tool.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	tool.Description = description.String
+	tool.Icon = icon.String
+	tool.Features = []string{}
+	if features.Valid && features.String != "" {
+		if err := json.Unmarshal([]byte(features.String), &tool.Features); err != nil {
+			return nil, fmt.Errorf("invalid features for tool %s: %w", tool.ID, err)
+		}
+	}
+
+	return &tool, nil
+}
+
+// toolMetadataEqual reports whether two tools share the same catalog metadata
+func toolMetadataEqual(a, b models.Tool) bool {
+	return a.Name == b.Name &&
+		a.Description == b.Description &&
+		a.Category == b.Category &&
+		a.Icon == b.Icon &&
+		reflect.DeepEqual(a.Features, b.Features)
+}
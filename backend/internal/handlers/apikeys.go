@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"web-tools-platform/backend/internal/database"
+	"web-tools-platform/backend/internal/models"
+	"web-tools-platform/backend/internal/services"
+)
+
+// GetAPIKeys handles GET /api/v1/admin/api-keys requests
+func (h *Handler) GetAPIKeys(c *gin.Context) {
+	keys, err := h.service.GetAPIKeys(c.Request.Context())
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get API keys")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve API keys",
+			Code:  "INTERNAL_ERROR",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateAPIKey handles POST /api/v1/admin/api-keys requests. The response is the
+// only time the key's secret is shown.
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	var request models.APIKeyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid request body",
+			Code:  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	key, err := h.service.CreateAPIKey(c.Request.Context(), request)
+	if errors.Is(err, services.ErrInvalidAPIKey) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid API key request",
+			Code:    "INVALID_REQUEST",
+			Details: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		logrus.WithError(err).Error("Failed to create API key")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to create API key",
+			Code:  "INTERNAL_ERROR",
+		})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"api_key_id": key.ID,
+		"scopes":     key.Scopes,
+	}).Info("API key created")
+	c.JSON(http.StatusCreated, key)
+}
+
+// RevokeAPIKey handles DELETE /api/v1/admin/api-keys/:keyId requests
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	keyID := c.Param("keyId")
+
+	err := h.service.RevokeAPIKey(c.Request.Context(), keyID)
+	if errors.Is(err, database.ErrNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "API key not found",
+			Code:  "NOT_FOUND",
+		})
+		return
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("api_key_id", keyID).Error("Failed to revoke API key")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to revoke API key",
+			Code:  "INTERNAL_ERROR",
+		})
+		return
+	}
+
+	logrus.WithField("api_key_id", keyID).Info("API key revoked")
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"web-tools-platform/backend/internal/auth"
+	"web-tools-platform/backend/internal/middleware"
+	"web-tools-platform/backend/internal/models"
+)
+
+// AuthHandler serves the OpenID Connect login endpoints
+type AuthHandler struct {
+	oidc *auth.OIDC
+}
+
+// NewAuthHandler creates a handler for the login endpoints. oidc is nil when
+// login is not configured, in which case only Me is useful.
+func NewAuthHandler(oidc *auth.OIDC) *AuthHandler {
+	return &AuthHandler{oidc: oidc}
+}
+
+// Login handles GET /api/v1/auth/login requests by redirecting to the provider.
+// The redirect query parameter is the local path to return to afterwards.
+func (h *AuthHandler) Login(c *gin.Context) {
+	if !h.enabled(c) {
+		return
+	}
+
+	authURL, state, err := h.oidc.AuthCodeURL(c.DefaultQuery("redirect", "/"))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to start login")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to start login",
+			Code:  "INTERNAL_ERROR",
+		})
+		return
+	}
+
+	h.setCookie(c, auth.StateCookie, state, int(auth.StateTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback handles GET /api/v1/auth/callback requests from the provider,
+// starting a session for the verified user
+func (h *AuthHandler) Callback(c *gin.Context) {
+	if !h.enabled(c) {
+		return
+	}
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "Login failed",
+			Code:    "UNAUTHORIZED",
+			Details: providerErr + ": " + c.Query("error_description"),
+		})
+		return
+	}
+
+	state, err := c.Cookie(auth.StateCookie)
+	if err != nil || c.Query("code") == "" || c.Query("state") == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid login callback",
+			Code:    "INVALID_REQUEST",
+			Details: "code, state and the login state cookie are required",
+		})
+		return
+	}
+	h.setCookie(c, auth.StateCookie, "", -1)
+
+	session, redirect, err := h.oidc.Exchange(c.Request.Context(), c.Query("code"), c.Query("state"), state)
+	if errors.Is(err, auth.ErrInvalidToken) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid login callback",
+			Code:    "INVALID_REQUEST",
+			Details: "login state is missing, expired or does not match",
+		})
+		return
+	}
+	if err != nil {
+		logrus.WithError(err).Warn("Login failed")
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error: "Login failed",
+			Code:  "UNAUTHORIZED",
+		})
+		return
+	}
+
+	h.setCookie(c, auth.SessionCookie, session, int(h.oidc.SessionTTL().Seconds()))
+	c.Redirect(http.StatusFound, redirect)
+}
+
+// Logout handles POST /api/v1/auth/logout requests by clearing the session
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if h.oidc != nil {
+		h.setCookie(c, auth.SessionCookie, "", -1)
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Me handles GET /api/v1/auth/me requests, returning the current principal
+func (h *AuthHandler) Me(c *gin.Context) {
+	principal := middleware.GetPrincipal(c)
+	if principal == nil {
+		principal = &models.Principal{Type: "anonymous", Scopes: []string{}}
+	}
+
+	c.JSON(http.StatusOK, principal)
+}
+
+// enabled writes a 404 response if login is not configured
+func (h *AuthHandler) enabled(c *gin.Context) bool {
+	if h.oidc == nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Login is not configured",
+			Code:  "NOT_FOUND",
+		})
+		return false
+	}
+	return true
+}
+
+func (h *AuthHandler) setCookie(c *gin.Context, name, value string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(name, value, maxAge, "/", "", h.oidc.SecureCookies(), true)
+}
@@ -1,44 +1,53 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 
 	"web-tools-platform/backend/internal/database"
+	"web-tools-platform/backend/internal/middleware"
 	"web-tools-platform/backend/internal/models"
 	"web-tools-platform/backend/internal/services"
 )
 
 // Handler holds the dependencies for HTTP handlers
 type Handler struct {
-	db      *database.DB
+	db      database.Store
 	service *services.Service
+	version APIVersion
 }
 
-// NewHandler creates a new handler instance
-func NewHandler(db *database.DB) *Handler {
+// NewHandler creates a new handler instance running tools within limits
+func NewHandler(db database.Store, limits services.Limits) *Handler {
 	return &Handler{
 		db:      db,
-		service: services.NewService(db),
+		service: services.NewService(db).WithLimits(limits),
+		version: V1,
 	}
 }
 
-// HealthCheck handles health check requests
-func (h *Handler) HealthCheck(c *gin.Context) {
-	response := models.HealthResponse{
-		Status:    "healthy",
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
-		Version:   "1.0.0",
+// userID returns the ID that per-user data such as settings and history is
+// stored under: the subject of logged-in users, the API key for key holders
+// and the client ID otherwise
+func userID(c *gin.Context) string {
+	if principal := middleware.GetPrincipal(c); principal != nil {
+		switch principal.Type {
+		case "user":
+			return "user:" + principal.ID
+		case "api_key":
+			return "apikey:" + principal.ID
+		}
 	}
-	c.JSON(http.StatusOK, response)
+	return c.GetString("clientID")
 }
 
-// GetTools handles GET /api/tools requests
+// GetTools handles GET /api/v1/tools requests
 func (h *Handler) GetTools(c *gin.Context) {
-	tools, err := h.service.GetTools()
+	tools, err := h.service.GetTools(c.Request.Context())
 	if err != nil {
 		logrus.WithError(err).Error("Failed to get tools")
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -51,7 +60,7 @@ func (h *Handler) GetTools(c *gin.Context) {
 	c.JSON(http.StatusOK, tools)
 }
 
-// GetTool handles GET /api/tools/:toolId requests
+// GetTool handles GET /api/v1/tools/:toolId requests
 func (h *Handler) GetTool(c *gin.Context) {
 	toolID := c.Param("toolId")
 	if toolID == "" {
@@ -62,20 +71,27 @@ func (h *Handler) GetTool(c *gin.Context) {
 		return
 	}
 
-	tool, err := h.service.GetTool(toolID)
-	if err != nil {
-		logrus.WithError(err).WithField("tool_id", toolID).Error("Failed to get tool")
+	tool, err := h.service.GetTool(c.Request.Context(), toolID)
+	if errors.Is(err, services.ErrToolNotFound) {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Error: "Tool not found",
 			Code:  "NOT_FOUND",
 		})
 		return
 	}
+	if err != nil {
+		logrus.WithError(err).WithField("tool_id", toolID).Error("Failed to get tool")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve tool",
+			Code:  "INTERNAL_ERROR",
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, tool)
 }
 
-// ProcessTool handles POST /api/tools/:toolId/process requests
+// ProcessTool handles POST /api/v1/tools/:toolId/process requests
 func (h *Handler) ProcessTool(c *gin.Context) {
 	toolID := c.Param("toolId")
 	if toolID == "" {
@@ -95,8 +111,33 @@ func (h *Handler) ProcessTool(c *gin.Context) {
 		return
 	}
 
-	response, err := h.service.ProcessTool(toolID, request)
+	response, err := h.service.ProcessTool(c.Request.Context(), userID(c), toolID, request)
 	if err != nil {
+		h.toolError(c, toolID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.version.ToolResponse(response))
+}
+
+// toolErrorStatus maps the kinds of services.ToolError to a status and code
+var toolErrorStatus = []struct {
+	kind   error
+	status int
+	code   string
+}{
+	{services.ErrToolNotFound, http.StatusNotFound, "TOOL_NOT_FOUND"},
+	{services.ErrInvalidInput, http.StatusUnprocessableEntity, "INVALID_INPUT"},
+	{services.ErrInvalidSettings, http.StatusBadRequest, "INVALID_SETTINGS"},
+	{services.ErrUnsupportedMode, http.StatusBadRequest, "UNSUPPORTED_MODE"},
+	{services.ErrInputTooLarge, http.StatusRequestEntityTooLarge, "INPUT_TOO_LARGE"},
+	{services.ErrToolTimeout, http.StatusGatewayTimeout, "TOOL_TIMEOUT"},
+}
+
+// toolError writes the response for a failed tool run
+func (h *Handler) toolError(c *gin.Context, toolID string, err error) {
+	var toolErr *services.ToolError
+	if !errors.As(err, &toolErr) {
 		logrus.WithError(err).WithField("tool_id", toolID).Error("Failed to process tool")
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error: "Failed to process tool",
@@ -105,26 +146,67 @@ func (h *Handler) ProcessTool(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	if h.version.LegacyToolErrors {
+		switch {
+		case errors.Is(err, services.ErrToolNotFound):
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error: "Failed to process tool",
+				Code:  "PROCESSING_ERROR",
+			})
+			return
+		case errors.Is(err, services.ErrInvalidInput), errors.Is(err, services.ErrInvalidSettings),
+			errors.Is(err, services.ErrUnsupportedMode):
+			c.JSON(http.StatusOK, h.version.ToolResponse(&models.ToolResponse{
+				Error:    toolErr.Message,
+				Metadata: toolErr.Details,
+			}))
+			return
+		}
+	}
+
+	response := models.ErrorResponse{Error: toolErr.Message}
+	if toolErr.Details != nil {
+		response.Details = toolErr.Details
+	}
+	for _, mapping := range toolErrorStatus {
+		if errors.Is(err, mapping.kind) {
+			response.Code = mapping.code
+			c.JSON(mapping.status, response)
+			return
+		}
+	}
+	c.JSON(http.StatusBadRequest, response)
 }
 
-// GetSettings handles GET /api/settings requests
+// GetSettings handles GET /api/v1/settings requests
 func (h *Handler) GetSettings(c *gin.Context) {
-	// For now, return default settings
-	// TODO: Implement user-specific settings
-	defaultSettings := map[string]interface{}{
-		"theme":       "light",
-		"language":    "en",
-		"fontSize":    "medium",
-		"autoSave":    true,
-		"toolHistory": true,
+	settings, err := h.service.GetSettings(c.Request.Context(), userID(c))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get settings")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve settings",
+			Code:  "INTERNAL_ERROR",
+		})
+		return
 	}
 
-	c.JSON(http.StatusOK, defaultSettings)
+	c.JSON(http.StatusOK, settings)
 }
 
-// UpdateSettings handles POST /api/settings requests
+// UpdateSettings handles POST /api/v1/settings requests, replacing all saved
+// settings
 func (h *Handler) UpdateSettings(c *gin.Context) {
+	h.saveSettings(c, h.service.ReplaceSettings)
+}
+
+// PatchSettings handles PATCH /api/v1/settings requests, updating only the
+// given settings
+func (h *Handler) PatchSettings(c *gin.Context) {
+	h.saveSettings(c, h.service.PatchSettings)
+}
+
+// saveSettings binds the request body and stores it with save
+func (h *Handler) saveSettings(c *gin.Context, save func(context.Context, string, map[string]interface{}) (map[string]interface{}, error)) {
 	var settings map[string]interface{}
 	if err := c.ShouldBindJSON(&settings); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
@@ -134,10 +216,27 @@ func (h *Handler) UpdateSettings(c *gin.Context) {
 		return
 	}
 
-	// TODO: Implement settings persistence
-	logrus.WithField("settings", settings).Info("Settings updated")
+	merged, err := save(c.Request.Context(), userID(c), settings)
+	if err != nil {
+		var validationErr *services.SettingsValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid settings: " + validationErr.Error(),
+				Code:    "INVALID_SETTINGS",
+				Details: gin.H{"fields": validationErr.Fields},
+			})
+			return
+		}
+		logrus.WithError(err).Error("Failed to save settings")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to save settings",
+			Code:  "INTERNAL_ERROR",
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Settings updated successfully",
+		"message":  "Settings updated successfully",
+		"settings": merged,
 	})
 }
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"web-tools-platform/backend/internal/models"
+	"web-tools-platform/backend/internal/services"
+)
+
+func TestToolErrorStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	legacy := V1
+	legacy.LegacyToolErrors = true
+
+	toolErr := func(kind error) error {
+		return &services.ToolError{Kind: kind, ToolID: "json", Message: "bad " + kind.Error()}
+	}
+
+	tests := []struct {
+		name    string
+		version APIVersion
+		err     error
+		status  int
+		code    string
+	}{
+		{"not found", V1, toolErr(services.ErrToolNotFound), http.StatusNotFound, "TOOL_NOT_FOUND"},
+		{"invalid input", V1, toolErr(services.ErrInvalidInput), http.StatusUnprocessableEntity, "INVALID_INPUT"},
+		{"invalid settings", V1, toolErr(services.ErrInvalidSettings), http.StatusBadRequest, "INVALID_SETTINGS"},
+		{"unsupported mode", V1, toolErr(services.ErrUnsupportedMode), http.StatusBadRequest, "UNSUPPORTED_MODE"},
+		{"too large", V1, toolErr(services.ErrInputTooLarge), http.StatusRequestEntityTooLarge, "INPUT_TOO_LARGE"},
+		{"timeout", V1, toolErr(services.ErrToolTimeout), http.StatusGatewayTimeout, "TOOL_TIMEOUT"},
+		{"wrapped", V1, fmt.Errorf("step 2: %w", toolErr(services.ErrInvalidInput)), http.StatusUnprocessableEntity, "INVALID_INPUT"},
+		{"untyped", V1, errors.New("disk full"), http.StatusInternalServerError, "PROCESSING_ERROR"},
+
+		{"legacy not found", legacy, toolErr(services.ErrToolNotFound), http.StatusInternalServerError, "PROCESSING_ERROR"},
+		{"legacy invalid input", legacy, toolErr(services.ErrInvalidInput), http.StatusOK, ""},
+		{"legacy invalid settings", legacy, toolErr(services.ErrInvalidSettings), http.StatusOK, ""},
+		{"legacy unsupported mode", legacy, toolErr(services.ErrUnsupportedMode), http.StatusOK, ""},
+		{"legacy too large", legacy, toolErr(services.ErrInputTooLarge), http.StatusRequestEntityTooLarge, "INPUT_TOO_LARGE"},
+		{"legacy timeout", legacy, toolErr(services.ErrToolTimeout), http.StatusGatewayTimeout, "TOOL_TIMEOUT"},
+		{"legacy untyped", legacy, errors.New("disk full"), http.StatusInternalServerError, "PROCESSING_ERROR"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		(&Handler{version: tt.version}).toolError(c, "json", tt.err)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.status)
+		}
+
+		// Legacy 200s carry the message as a failed tool result
+		if tt.status == http.StatusOK {
+			var response models.ToolResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("%s: decoding the response: %v", tt.name, err)
+			}
+			var want *services.ToolError
+			errors.As(tt.err, &want)
+			if response.Error != want.Message {
+				t.Errorf("%s: error = %q, want %q", tt.name, response.Error, want.Message)
+			}
+			continue
+		}
+
+		var response models.ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("%s: decoding the response: %v", tt.name, err)
+		}
+		if response.Code != tt.code {
+			t.Errorf("%s: code = %q, want %q", tt.name, response.Code, tt.code)
+		}
+	}
+}
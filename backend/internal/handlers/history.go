@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"web-tools-platform/backend/internal/database"
+	"web-tools-platform/backend/internal/models"
+	"web-tools-platform/backend/internal/services"
+)
+
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
+// GetHistory handles GET /api/v1/history requests. It supports the query
+// parameters tool, from, to (RFC 3339 or YYYY-MM-DD), page and limit.
+func (h *Handler) GetHistory(c *gin.Context) {
+	filter, err := historyFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid query parameters",
+			Code:    "INVALID_REQUEST",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	page, limit, ok := historyPagination(c)
+	if !ok {
+		return
+	}
+	filter.Limit = limit
+	filter.Offset = (page - 1) * limit
+
+	entries, total, err := h.service.GetHistory(c.Request.Context(), filter)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get history")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve history",
+			Code:  "INTERNAL_ERROR",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HistoryPage{
+		Entries: entries,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+	})
+}
+
+// SearchHistory handles GET /api/v1/history/search requests. q is required;
+// tool, from, to, page and limit behave as for GET /api/v1/history.
+func (h *Handler) SearchHistory(c *gin.Context) {
+	filter, err := historyFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid query parameters",
+			Code:    "INVALID_REQUEST",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	page, limit, ok := historyPagination(c)
+	if !ok {
+		return
+	}
+	filter.Limit = limit
+	filter.Offset = (page - 1) * limit
+
+	results, total, err := h.service.SearchHistory(c.Request.Context(), c.Query("q"), filter)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSearch) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: "Search query is required",
+				Code:  "INVALID_REQUEST",
+			})
+			return
+		}
+		logrus.WithError(err).Error("Failed to search history")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to search history",
+			Code:  "INTERNAL_ERROR",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HistorySearchPage{
+		Results: results,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+	})
+}
+
+// GetHistoryEntry handles GET /api/v1/history/:id requests
+func (h *Handler) GetHistoryEntry(c *gin.Context) {
+	id, ok := historyID(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.service.GetHistoryEntry(c.Request.Context(), userID(c), id)
+	if err != nil {
+		h.historyError(c, id, err, "Failed to retrieve history entry")
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteHistoryEntry handles DELETE /api/v1/history/:id requests
+func (h *Handler) DeleteHistoryEntry(c *gin.Context) {
+	id, ok := historyID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteHistoryEntry(c.Request.Context(), userID(c), id); err != nil {
+		h.historyError(c, id, err, "Failed to delete history entry")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ClearHistory handles DELETE /api/v1/history requests. The tool, from and to
+// query parameters restrict which entries are removed.
+func (h *Handler) ClearHistory(c *gin.Context) {
+	filter, err := historyFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid query parameters",
+			Code:    "INVALID_REQUEST",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	deleted, err := h.service.ClearHistory(c.Request.Context(), filter)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to clear history")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to clear history",
+			Code:  "INTERNAL_ERROR",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deleted": deleted,
+	})
+}
+
+// historyError writes the response for a failed history entry operation
+func (h *Handler) historyError(c *gin.Context, id int, err error, message string) {
+	if errors.Is(err, database.ErrNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "History entry not found",
+			Code:  "NOT_FOUND",
+		})
+		return
+	}
+
+	logrus.WithError(err).WithField("history_id", id).Error(message)
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		Error: message,
+		Code:  "INTERNAL_ERROR",
+	})
+}
+
+// historyID parses the :id path parameter, writing a 400 response if invalid
+func historyID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid history entry ID",
+			Code:  "INVALID_REQUEST",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// historyPagination parses the page and limit query parameters, writing a 400
+// response if they are invalid
+func historyPagination(c *gin.Context) (int, int, bool) {
+	page, err := queryInt(c, "page", 1)
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid query parameters",
+			Code:    "INVALID_REQUEST",
+			Details: "page must be a positive integer",
+		})
+		return 0, 0, false
+	}
+
+	limit, err := queryInt(c, "limit", defaultHistoryLimit)
+	if err != nil || limit < 1 || limit > maxHistoryLimit {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid query parameters",
+			Code:    "INVALID_REQUEST",
+			Details: fmt.Sprintf("limit must be between 1 and %d", maxHistoryLimit),
+		})
+		return 0, 0, false
+	}
+
+	return page, limit, true
+}
+
+// historyFilter builds a history filter for the caller's entries from the
+// tool, from and to query parameters. A date-only "to" includes the whole day.
+func historyFilter(c *gin.Context) (database.HistoryFilter, error) {
+	filter := database.HistoryFilter{
+		UserID: userID(c),
+		ToolID: c.Query("tool"),
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, _, err := parseQueryTime(from)
+		if err != nil {
+			return filter, fmt.Errorf("invalid from: %s", from)
+		}
+		filter.From = t
+	}
+	if to := c.Query("to"); to != "" {
+		t, dateOnly, err := parseQueryTime(to)
+		if err != nil {
+			return filter, fmt.Errorf("invalid to: %s", to)
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		filter.To = t
+	}
+
+	return filter, nil
+}
+
+// parseQueryTime parses an RFC 3339 timestamp or a YYYY-MM-DD date
+func parseQueryTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	return t, true, err
+}
+
+// queryInt parses an integer query parameter, returning def when it is absent
+func queryInt(c *gin.Context, key string, def int) (int, error) {
+	value := c.Query(key)
+	if value == "" {
+		return def, nil
+	}
+	return strconv.Atoi(value)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"web-tools-platform/backend/internal/models"
+	"web-tools-platform/backend/internal/services"
+)
+
+// ProcessPipeline handles POST /api/v1/pipelines/process requests
+func (h *Handler) ProcessPipeline(c *gin.Context) {
+	var request models.PipelineRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid request body",
+			Code:  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	response, err := h.service.ProcessPipeline(c.Request.Context(), request)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPipeline) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid pipeline",
+				Code:    "INVALID_PIPELINE",
+				Details: err.Error(),
+			})
+			return
+		}
+		logrus.WithError(err).Error("Failed to process pipeline")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to process pipeline",
+			Code:  "PROCESSING_ERROR",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.version.ToolResponse(response))
+}
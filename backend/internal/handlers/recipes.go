@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"web-tools-platform/backend/internal/database"
+	"web-tools-platform/backend/internal/models"
+	"web-tools-platform/backend/internal/services"
+)
+
+// GetRecipes handles GET /api/v1/recipes requests
+func (h *Handler) GetRecipes(c *gin.Context) {
+	recipes, err := h.service.GetRecipes(c.Request.Context())
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get recipes")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve recipes",
+			Code:  "INTERNAL_ERROR",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, recipes)
+}
+
+// GetRecipe handles GET /api/v1/recipes/:recipeId requests
+func (h *Handler) GetRecipe(c *gin.Context) {
+	recipeID := c.Param("recipeId")
+
+	recipe, err := h.service.GetRecipe(c.Request.Context(), recipeID)
+	if err != nil {
+		h.recipeError(c, recipeID, err, "Failed to retrieve recipe")
+		return
+	}
+
+	c.JSON(http.StatusOK, recipe)
+}
+
+// CreateRecipe handles POST /api/v1/recipes requests
+func (h *Handler) CreateRecipe(c *gin.Context) {
+	var request models.RecipeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid request body",
+			Code:  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	recipe, err := h.service.CreateRecipe(c.Request.Context(), request)
+	if err != nil {
+		h.recipeError(c, "", err, "Failed to create recipe")
+		return
+	}
+
+	c.JSON(http.StatusCreated, recipe)
+}
+
+// UpdateRecipe handles PUT /api/v1/recipes/:recipeId requests
+func (h *Handler) UpdateRecipe(c *gin.Context) {
+	recipeID := c.Param("recipeId")
+
+	var request models.RecipeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid request body",
+			Code:  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	recipe, err := h.service.UpdateRecipe(c.Request.Context(), recipeID, request)
+	if err != nil {
+		h.recipeError(c, recipeID, err, "Failed to update recipe")
+		return
+	}
+
+	c.JSON(http.StatusOK, recipe)
+}
+
+// DeleteRecipe handles DELETE /api/v1/recipes/:recipeId requests
+func (h *Handler) DeleteRecipe(c *gin.Context) {
+	recipeID := c.Param("recipeId")
+
+	if err := h.service.DeleteRecipe(c.Request.Context(), recipeID); err != nil {
+		h.recipeError(c, recipeID, err, "Failed to delete recipe")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RunRecipe handles POST /api/v1/recipes/:recipeId/run requests
+func (h *Handler) RunRecipe(c *gin.Context) {
+	recipeID := c.Param("recipeId")
+
+	var request models.ToolRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid request body",
+			Code:  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	response, err := h.service.RunRecipe(c.Request.Context(), recipeID, request.Input)
+	if err != nil {
+		h.recipeError(c, recipeID, err, "Failed to run recipe")
+		return
+	}
+
+	c.JSON(http.StatusOK, h.version.ToolResponse(response))
+}
+
+// recipeError writes the response for a failed recipe operation
+func (h *Handler) recipeError(c *gin.Context, recipeID string, err error, message string) {
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Recipe not found",
+			Code:  "NOT_FOUND",
+		})
+	case errors.Is(err, services.ErrInvalidRecipe), errors.Is(err, services.ErrInvalidPipeline):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid recipe",
+			Code:    "INVALID_RECIPE",
+			Details: err.Error(),
+		})
+	default:
+		logrus.WithError(err).WithField("recipe_id", recipeID).Error(message)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: message,
+			Code:  "INTERNAL_ERROR",
+		})
+	}
+}
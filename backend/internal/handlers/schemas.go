@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"web-tools-platform/backend/internal/database"
+	"web-tools-platform/backend/internal/models"
+	"web-tools-platform/backend/internal/services"
+)
+
+// maxSchemaFileSize limits the size of each uploaded schema file
+const maxSchemaFileSize = 4 << 20
+
+// GetSchemas handles GET /api/v1/schemas requests
+func (h *Handler) GetSchemas(c *gin.Context) {
+	schemas, err := h.service.GetSchemas(c.Request.Context())
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get schemas")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to retrieve schemas",
+			Code:  "INTERNAL_ERROR",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas)
+}
+
+// GetSchema handles GET /api/v1/schemas/:schemaId requests
+func (h *Handler) GetSchema(c *gin.Context) {
+	schemaID := c.Param("schemaId")
+
+	schema, err := h.service.GetSchema(c.Request.Context(), schemaID)
+	if err != nil {
+		h.schemaError(c, schemaID, err, "Failed to retrieve schema")
+		return
+	}
+
+	c.JSON(http.StatusOK, schema)
+}
+
+// CreateSchema handles POST /api/v1/schemas requests. Files are uploaded as
+// multipart form data in one or more "file" fields: either .proto sources or
+// a single compiled FileDescriptorSet.
+func (h *Handler) CreateSchema(c *gin.Context) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid multipart form",
+			Code:  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	uploads := form.File["file"]
+	if len(uploads) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "At least one schema file is required",
+			Code:  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	files := make(map[string][]byte, len(uploads))
+	for _, upload := range uploads {
+		if upload.Size > maxSchemaFileSize {
+			c.JSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+				Error:   "Schema file too large",
+				Code:    "FILE_TOO_LARGE",
+				Details: upload.Filename,
+			})
+			return
+		}
+
+		f, err := upload.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Failed to read uploaded file",
+				Code:    "INVALID_REQUEST",
+				Details: upload.Filename,
+			})
+			return
+		}
+		content, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Failed to read uploaded file",
+				Code:    "INVALID_REQUEST",
+				Details: upload.Filename,
+			})
+			return
+		}
+		files[filepath.ToSlash(upload.Filename)] = content
+	}
+
+	name := c.PostForm("name")
+	if name == "" {
+		name = uploads[0].Filename
+	}
+
+	schema, err := h.service.CreateSchema(c.Request.Context(), name, files)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSchema) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid schema",
+				Code:    "INVALID_SCHEMA",
+				Details: err.Error(),
+			})
+			return
+		}
+		logrus.WithError(err).Error("Failed to create schema")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error: "Failed to store schema",
+			Code:  "INTERNAL_ERROR",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, schema)
+}
+
+// DeleteSchema handles DELETE /api/v1/schemas/:schemaId requests
+func (h *Handler) DeleteSchema(c *gin.Context) {
+	schemaID := c.Param("schemaId")
+
+	if err := h.service.DeleteSchema(c.Request.Context(), schemaID); err != nil {
+		h.schemaError(c, schemaID, err, "Failed to delete schema")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DecodeWithSchema handles POST /api/v1/schemas/:schemaId/decode requests
+func (h *Handler) DecodeWithSchema(c *gin.Context) {
+	schemaID := c.Param("schemaId")
+
+	var request models.SchemaDecodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.MessageType == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error: "Invalid request body",
+			Code:  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	response, err := h.service.DecodeWithSchema(c.Request.Context(), schemaID, request)
+	var toolErr *services.ToolError
+	if errors.As(err, &toolErr) {
+		h.toolError(c, schemaID, err)
+		return
+	}
+	if err != nil {
+		h.schemaError(c, schemaID, err, "Failed to decode with schema")
+		return
+	}
+
+	c.JSON(http.StatusOK, h.version.ToolResponse(response))
+}
+
+// schemaError writes the response for a failed schema lookup or operation
+func (h *Handler) schemaError(c *gin.Context, schemaID string, err error, message string) {
+	if errors.Is(err, database.ErrNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error: "Schema not found",
+			Code:  "NOT_FOUND",
+		})
+		return
+	}
+
+	logrus.WithError(err).WithField("schema_id", schemaID).Error(message)
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		Error: message,
+		Code:  "INTERNAL_ERROR",
+	})
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import "web-tools-platform/backend/internal/models"
+
+// APIVersion holds the response shapes of one API version. Every version
+// is served by the same handlers; a version that changes a response shape
+// supplies its own renderer instead of changing the handlers, so routes of
+// older versions keep answering as before.
+type APIVersion struct {
+	Name string
+	// ToolResponse renders the result of running a tool, pipeline, recipe
+	// or schema conversion
+	ToolResponse func(*models.ToolResponse) interface{}
+	// LegacyToolErrors answers tool errors as releases before typed errors
+	// did: invalid input and settings with 200 and the message in the
+	// ToolResponse, unknown tools with 500
+	LegacyToolErrors bool
+}
+
+// V1 is the stable API, served under /api/v1 and the deprecated /api
+var V1 = APIVersion{
+	Name: "v1",
+	ToolResponse: func(response *models.ToolResponse) interface{} {
+		return response
+	},
+}
+
+// ForVersion returns handlers that render responses as version does
+func (h *Handler) ForVersion(version APIVersion) *Handler {
+	versioned := *h
+	versioned.version = version
+	return &versioned
+}
@@ -0,0 +1,108 @@
+// Package metrics defines the Prometheus metrics exported on /metrics and
+// the registry they are collected from.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "webtools"
+
+// Registry holds every metric served by Handler, including the Go runtime
+// and process collectors
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+// sizeBuckets cover payloads from 64 B to 16 MiB
+var sizeBuckets = prometheus.ExponentialBuckets(64, 4, 10)
+
+var (
+	// HTTPRequests counts requests by method, route pattern and status code
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration observes request latency by method, route pattern
+	// and status code
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// HTTPErrors counts error responses by route pattern and ErrorResponse code
+	HTTPErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_errors_total",
+		Help:      "Error responses by route and error code.",
+	}, []string{"route", "code"})
+
+	// ToolProcessed counts tool runs by tool and result: "ok", "tool_error"
+	// when the tool reported an error for its input, or "failed"
+	ToolProcessed = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_process_total",
+		Help:      "Tool runs by tool and result.",
+	}, []string{"tool", "result"})
+
+	// ToolDuration observes how long each tool takes to process its input
+	ToolDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_process_duration_seconds",
+		Help:      "Tool processing time by tool.",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+	}, []string{"tool"})
+
+	// ToolInputBytes observes the size of tool inputs
+	ToolInputBytes = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_input_bytes",
+		Help:      "Size of tool inputs by tool.",
+		Buckets:   sizeBuckets,
+	}, []string{"tool"})
+
+	// ToolOutputBytes observes the size of tool outputs
+	ToolOutputBytes = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_output_bytes",
+		Help:      "Size of tool outputs by tool.",
+		Buckets:   sizeBuckets,
+	}, []string{"tool"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// ObserveTool records a single tool run
+func ObserveTool(toolID, result string, duration time.Duration, inputBytes, outputBytes int) {
+	ToolProcessed.WithLabelValues(toolID, result).Inc()
+	ToolDuration.WithLabelValues(toolID).Observe(duration.Seconds())
+	ToolInputBytes.WithLabelValues(toolID).Observe(float64(inputBytes))
+	ToolOutputBytes.WithLabelValues(toolID).Observe(float64(outputBytes))
+}
+
+// RegisterDB exports the connection pool statistics of db, labelled with
+// name
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the registry in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"web-tools-platform/backend/internal/auth"
+	"web-tools-platform/backend/internal/models"
+	"web-tools-platform/backend/internal/services"
+)
+
+// principalKey is the gin context key holding the *models.Principal
+const principalKey = "principal"
+
+// Authenticator resolves API key secrets to principals. It returns
+// services.ErrInvalidCredentials for unknown or revoked keys.
+type Authenticator interface {
+	Authenticate(ctx context.Context, secret string) (*models.Principal, error)
+}
+
+// SessionVerifier resolves session cookies of users logged in to the web UI
+type SessionVerifier interface {
+	Principal(sessionCookie string) (*models.Principal, error)
+}
+
+// AuthConfig configures the Auth middleware
+type AuthConfig struct {
+	Authenticator Authenticator
+	// Sessions is set when OpenID Connect login is enabled
+	Sessions SessionVerifier
+	// AnonymousScopes are granted to requests without an API key or session
+	AnonymousScopes []string
+	// FailureLimit bounds the failed authentications per client IP, counted
+	// in FailureStore. Once it is used up, requests presenting an API key get
+	// 429 before the key is checked. A zero limit disables it.
+	FailureLimit Limit
+	FailureStore RateLimitStore
+}
+
+// Auth identifies the caller from an `Authorization: Bearer <key>` header or
+// a session cookie and stores the principal on the context. Other requests
+// continue as an anonymous principal holding the configured anonymous
+// scopes; invalid keys are rejected while invalid sessions are ignored.
+func Auth(config AuthConfig) gin.HandlerFunc {
+	if config.FailureStore == nil {
+		config.FailureStore = NewMemoryStore()
+	}
+
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Set(principalKey, sessionPrincipal(c, config))
+			c.Next()
+			return
+		}
+
+		if authFailuresExhausted(c, config) {
+			return
+		}
+
+		secret, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || strings.TrimSpace(secret) == "" {
+			recordAuthFailure(c, config)
+			abortUnauthorized(c, "Authorization header must use the Bearer scheme")
+			return
+		}
+
+		principal, err := config.Authenticator.Authenticate(c.Request.Context(), strings.TrimSpace(secret))
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			recordAuthFailure(c, config)
+			abortUnauthorized(c, "Invalid or revoked API key")
+			return
+		}
+		if err != nil {
+			logrus.WithError(err).Error("Failed to authenticate request")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error: "Failed to authenticate request",
+				Code:  "INTERNAL_ERROR",
+			})
+			return
+		}
+
+		c.Set(principalKey, principal)
+		c.Next()
+	}
+}
+
+// authFailuresExhausted rejects the request with 429 and returns true when
+// the client IP has used up its failed authentications
+func authFailuresExhausted(c *gin.Context, config AuthConfig) bool {
+	if config.FailureLimit.Requests <= 0 {
+		return false
+	}
+
+	result, err := config.FailureStore.Peek(c.Request.Context(), authFailureKey(c), config.FailureLimit, time.Now())
+	if err != nil {
+		// Fail open like RateLimit
+		logrus.WithError(err).Warn("Rate limit store unavailable")
+		return false
+	}
+	if result.Allowed {
+		return false
+	}
+
+	limit := config.FailureLimit
+	abortRateLimited(c, fmt.Sprintf("Too many failed authentications, limit is %d per %s", limit.Requests, limit.Period), result)
+	return true
+}
+
+// recordAuthFailure counts a failed authentication against the client IP
+func recordAuthFailure(c *gin.Context, config AuthConfig) {
+	if config.FailureLimit.Requests <= 0 {
+		return
+	}
+
+	if _, err := config.FailureStore.Take(c.Request.Context(), authFailureKey(c), config.FailureLimit, time.Now()); err != nil {
+		logrus.WithError(err).Warn("Rate limit store unavailable")
+	}
+}
+
+func authFailureKey(c *gin.Context) string {
+	return "auth-failures|ip:" + c.ClientIP()
+}
+
+// sessionPrincipal returns the logged-in user of the session cookie, falling
+// back to an anonymous principal
+func sessionPrincipal(c *gin.Context, config AuthConfig) *models.Principal {
+	if config.Sessions != nil {
+		if cookie, err := c.Cookie(auth.SessionCookie); err == nil && cookie != "" {
+			if principal, err := config.Sessions.Principal(cookie); err == nil {
+				return principal
+			}
+		}
+	}
+	return &models.Principal{Type: "anonymous", Scopes: config.AnonymousScopes}
+}
+
+// RequireScope rejects requests whose principal lacks scope, with 401 for
+// anonymous callers and 403 for users and API keys
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := GetPrincipal(c)
+		if principal != nil && principal.HasScope(scope) {
+			c.Next()
+			return
+		}
+
+		if principal == nil || principal.Type == "anonymous" {
+			abortUnauthorized(c, "Log in or send an API key")
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Insufficient scope",
+			Code:    "FORBIDDEN",
+			Details: fmt.Sprintf("The %s scope is required", scope),
+		})
+	}
+}
+
+// GetPrincipal returns the principal stored by Auth, or nil
+func GetPrincipal(c *gin.Context) *models.Principal {
+	value, ok := c.Get(principalKey)
+	if !ok {
+		return nil
+	}
+	principal, _ := value.(*models.Principal)
+	return principal
+}
+
+func abortUnauthorized(c *gin.Context, details string) {
+	c.Header("WWW-Authenticate", `Bearer realm="api"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+		Error:   "Unauthorized",
+		Code:    "UNAUTHORIZED",
+		Details: details,
+	})
+}
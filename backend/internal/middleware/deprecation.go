@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecated marks responses as coming from a deprecated API. It sets the
+// Deprecation header (RFC 9745) to since, the Sunset header (RFC 8594) to
+// sunset unless it is zero, and links to the replacement of the requested
+// path given by successor.
+func Deprecated(since, sunset time.Time, successor func(path string) string) gin.HandlerFunc {
+	deprecation := fmt.Sprintf("@%d", since.Unix())
+	var sunsetHeader string
+	if !sunset.IsZero() {
+		sunsetHeader = sunset.UTC().Format(http.TimeFormat)
+	}
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		if sunsetHeader != "" {
+			c.Header("Sunset", sunsetHeader)
+		}
+		c.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor(c.Request.URL.Path)))
+		c.Next()
+	}
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"web-tools-platform/backend/internal/metrics"
+	"web-tools-platform/backend/internal/models"
+)
+
+// maxErrorBodyCapture bounds how much of an error response is kept to read
+// its ErrorResponse code
+const maxErrorBodyCapture = 4096
+
+// unmatchedRoute labels requests that matched no route, keeping arbitrary
+// paths out of the metric labels
+const unmatchedRoute = "unmatched"
+
+// Metrics records request counts and latency by route pattern and status,
+// and error responses by their ErrorResponse code. It should run before
+// Recovery so that panics are counted as 500 responses.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startTime := time.Now()
+		writer := &errorCaptureWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, route, status).
+			Observe(time.Since(startTime).Seconds())
+
+		if code := writer.errorCode(); code != "" {
+			metrics.HTTPErrors.WithLabelValues(route, code).Inc()
+		}
+	}
+}
+
+// errorCaptureWriter keeps the start of error response bodies
+type errorCaptureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *errorCaptureWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *errorCaptureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *errorCaptureWriter) capture(data []byte) {
+	if w.Status() < 400 || w.body.Len() >= maxErrorBodyCapture {
+		return
+	}
+	if remaining := maxErrorBodyCapture - w.body.Len(); len(data) > remaining {
+		data = data[:remaining]
+	}
+	w.body.Write(data)
+}
+
+// errorCode returns the code of an ErrorResponse body, "UNKNOWN" for other
+// error responses and "" for successful ones
+func (w *errorCaptureWriter) errorCode() string {
+	if w.Status() < 400 {
+		return ""
+	}
+
+	var response models.ErrorResponse
+	if err := json.Unmarshal(w.body.Bytes(), &response); err != nil || response.Code == "" {
+		return "UNKNOWN"
+	}
+	return response.Code
+}
@@ -1,11 +1,15 @@
 package middleware
 
 import (
+	"net/http"
+	"regexp"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // RequestID adds a unique request ID to each request
@@ -17,6 +21,37 @@ func RequestID() gin.HandlerFunc {
 		}
 		c.Set("requestID", requestID)
 		c.Header("X-Request-ID", requestID)
+
+		// Tie the request ID to the trace started by the tracing middleware
+		if span := trace.SpanFromContext(c.Request.Context()); span.IsRecording() {
+			span.SetAttributes(attribute.String("http.request_id", requestID))
+		}
+
+		c.Next()
+	}
+}
+
+// clientIDCookie stores the anonymous client ID between visits
+const clientIDCookie = "client_id"
+
+// clientIDPattern restricts client IDs supplied by callers
+var clientIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ClientID identifies the calling client for per-client data such as settings
+// and history. The ID is taken from the X-Client-ID header or the client_id
+// cookie; otherwise a new one is generated and stored in the cookie.
+func ClientID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clientID := c.GetHeader("X-Client-ID")
+		if !clientIDPattern.MatchString(clientID) {
+			clientID, _ = c.Cookie(clientIDCookie)
+		}
+		if !clientIDPattern.MatchString(clientID) {
+			clientID = uuid.New().String()
+			c.SetSameSite(http.SameSiteLaxMode)
+			c.SetCookie(clientIDCookie, clientID, 365*24*60*60, "/", "", c.Request.TLS != nil, true)
+		}
+		c.Set("clientID", clientID)
 		c.Next()
 	}
 }
@@ -33,7 +68,7 @@ func Logger() gin.HandlerFunc {
 		duration := time.Since(startTime)
 		requestID, _ := c.Get("requestID")
 
-		logrus.WithFields(logrus.Fields{
+		fields := logrus.Fields{
 			"request_id":  requestID,
 			"method":      c.Request.Method,
 			"path":        c.Request.URL.Path,
@@ -41,22 +76,14 @@ func Logger() gin.HandlerFunc {
 			"duration_ms": duration.Milliseconds(),
 			"client_ip":   c.ClientIP(),
 			"user_agent":  c.Request.UserAgent(),
-		}).Info("Request processed")
-	}
-}
-
-// RateLimit provides basic rate limiting (placeholder for now)
-func RateLimit() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// TODO: Implement rate limiting logic
-		c.Next()
-	}
-}
+		}
+		if principal := GetPrincipal(c); principal != nil && principal.ID != "" {
+			fields["principal"] = principal.Type + ":" + principal.ID
+		}
+		if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.IsValid() {
+			fields["trace_id"] = spanContext.TraceID().String()
+		}
 
-// Auth provides authentication middleware (placeholder for now)
-func Auth() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// TODO: Implement authentication logic
-		c.Next()
+		logrus.WithFields(fields).Info("Request processed")
 	}
 }
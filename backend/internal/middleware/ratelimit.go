@@ -0,0 +1,300 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"web-tools-platform/backend/internal/database"
+	"web-tools-platform/backend/internal/models"
+)
+
+// Limit allows Requests requests per Period, refilled continuously, with
+// bursts of up to Requests
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// String formats the limit as <requests>/<period>
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// Bucket is the state of a token bucket
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// RateLimitResult is the outcome of taking a token from a bucket
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is the time until the next token is available when the
+	// request was not allowed
+	RetryAfter time.Duration
+}
+
+// Take refills the bucket for the time elapsed since it was last updated and
+// removes one token if one is available. A zero bucket is treated as full.
+func (b *Bucket) Take(limit Limit, now time.Time) RateLimitResult {
+	b.refill(limit, now)
+
+	allowed := b.Tokens >= 1
+	if allowed {
+		b.Tokens--
+	}
+	return b.result(limit, allowed)
+}
+
+// Peek refills the bucket like Take and reports whether a token is
+// available, without removing it
+func (b *Bucket) Peek(limit Limit, now time.Time) RateLimitResult {
+	b.refill(limit, now)
+	return b.result(limit, b.Tokens >= 1)
+}
+
+func (b *Bucket) refill(limit Limit, now time.Time) {
+	capacity := float64(limit.Requests)
+	if b.UpdatedAt.IsZero() {
+		b.Tokens = capacity
+	} else if elapsed := now.Sub(b.UpdatedAt); elapsed > 0 {
+		b.Tokens = math.Min(capacity, b.Tokens+elapsed.Seconds()/limit.perToken().Seconds())
+	}
+	b.UpdatedAt = now
+}
+
+func (b *Bucket) result(limit Limit, allowed bool) RateLimitResult {
+	perToken := limit.perToken()
+
+	result := RateLimitResult{Allowed: allowed, Remaining: int(b.Tokens)}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - b.Tokens) * float64(perToken))
+	}
+	result.Reset = time.Duration((float64(limit.Requests) - b.Tokens) * float64(perToken))
+	return result
+}
+
+// perToken is the time it takes to refill one token
+func (l Limit) perToken() time.Duration {
+	return l.Period / time.Duration(l.Requests)
+}
+
+// RateLimitStore keeps token buckets. Take must refill and take from the
+// bucket for key atomically, and Peek must leave it unchanged; a store shared
+// between instances, such as the database, lets them enforce a single limit.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (RateLimitResult, error)
+	Peek(ctx context.Context, key string, limit Limit, now time.Time) (RateLimitResult, error)
+}
+
+// MemoryStore keeps token buckets in process memory
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+}
+
+type memoryBucket struct {
+	Bucket
+	limit Limit
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*memoryBucket{}}
+}
+
+// Take implements RateLimitStore
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{limit: limit}
+		s.buckets[key] = bucket
+	}
+	bucket.limit = limit
+
+	return bucket.Take(limit, now), nil
+}
+
+// Peek implements RateLimitStore
+func (s *MemoryStore) Peek(ctx context.Context, key string, limit Limit, now time.Time) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var bucket Bucket
+	if stored, ok := s.buckets[key]; ok {
+		bucket = stored.Bucket
+	}
+	return bucket.Peek(limit, now), nil
+}
+
+// sweep drops buckets that have refilled completely, since a missing bucket
+// behaves the same as a full one
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.UpdatedAt) >= bucket.limit.Period {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// DatabaseStore keeps token buckets in the rate_limits table so that every
+// instance using the same database shares them
+type DatabaseStore struct {
+	db *database.DB
+
+	mu        sync.Mutex
+	maxPeriod time.Duration
+	lastSweep time.Time
+}
+
+// NewDatabaseStore creates a store backed by db
+func NewDatabaseStore(db *database.DB) *DatabaseStore {
+	return &DatabaseStore{db: db}
+}
+
+// Take implements RateLimitStore
+func (s *DatabaseStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (RateLimitResult, error) {
+	s.sweep(ctx, limit, now)
+
+	var result RateLimitResult
+	err := s.db.UpdateRateLimitBucket(ctx, key, func(tokens float64, updatedAt time.Time) (float64, time.Time) {
+		bucket := Bucket{Tokens: tokens, UpdatedAt: updatedAt}
+		result = bucket.Take(limit, now)
+		return bucket.Tokens, bucket.UpdatedAt
+	})
+
+	return result, err
+}
+
+// Peek implements RateLimitStore
+func (s *DatabaseStore) Peek(ctx context.Context, key string, limit Limit, now time.Time) (RateLimitResult, error) {
+	tokens, updatedAt, err := s.db.GetRateLimitBucket(ctx, key)
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	bucket := Bucket{Tokens: tokens, UpdatedAt: updatedAt}
+	return bucket.Peek(limit, now), nil
+}
+
+// sweep prunes buckets idle for longer than the longest period seen, which
+// have refilled completely
+func (s *DatabaseStore) sweep(ctx context.Context, limit Limit, now time.Time) {
+	s.mu.Lock()
+	if limit.Period > s.maxPeriod {
+		s.maxPeriod = limit.Period
+	}
+	if now.Sub(s.lastSweep) < time.Minute {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSweep = now
+	before := now.Add(-s.maxPeriod)
+	s.mu.Unlock()
+
+	if _, err := s.db.PruneRateLimitBuckets(ctx, before); err != nil {
+		logrus.WithError(err).Warn("Failed to prune rate limit buckets")
+	}
+}
+
+// RateLimitConfig configures the RateLimit middleware
+type RateLimitConfig struct {
+	// Default applies to every route without an entry in Routes
+	Default Limit
+	// Routes holds stricter or looser limits keyed by "METHOD /route/:param"
+	Routes map[string]Limit
+	Store  RateLimitStore
+}
+
+// RateLimit enforces token bucket limits per client. Clients are identified
+// by their API key when Auth has authenticated one and by IP address
+// otherwise, and every route with its own limit gets a separate bucket.
+func RateLimit(config RateLimitConfig) gin.HandlerFunc {
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		limit, ok := config.Routes[route]
+		if !ok {
+			route, limit = "default", config.Default
+		}
+		if limit.Requests <= 0 {
+			c.Next()
+			return
+		}
+
+		result, err := config.Store.Take(c.Request.Context(), route+"|"+rateLimitClient(c), limit, time.Now())
+		if err != nil {
+			// Fail open so that a store outage does not take the API down
+			logrus.WithError(err).WithField("route", route).Warn("Rate limit store unavailable")
+			c.Next()
+			return
+		}
+
+		setRateLimitHeaders(c, limit, result)
+		if !result.Allowed {
+			abortRateLimited(c, fmt.Sprintf("Limit is %d requests per %s", limit.Requests, limit.Period), result)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// setRateLimitHeaders reports the state of the caller's bucket
+func setRateLimitHeaders(c *gin.Context, limit Limit, result RateLimitResult) {
+	c.Header("RateLimit-Limit", strconv.Itoa(limit.Requests))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, ceilSeconds(limit.Period)))
+}
+
+// abortRateLimited rejects a request whose bucket is empty with 429 and a
+// Retry-After header; reason describes the limit
+func abortRateLimited(c *gin.Context, reason string, result RateLimitResult) {
+	retryAfter := ceilSeconds(result.RetryAfter)
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, models.ErrorResponse{
+		Error:   "Rate limit exceeded",
+		Code:    "RATE_LIMITED",
+		Details: fmt.Sprintf("%s, retry in %d seconds", reason, retryAfter),
+	})
+}
+
+// rateLimitClient returns the bucket key of the caller: the API key ID for
+// key holders and the IP address otherwise, which gin only takes from
+// X-Forwarded-For when the request came through a trusted proxy
+func rateLimitClient(c *gin.Context) string {
+	if principal := GetPrincipal(c); principal != nil && principal.Type == "api_key" {
+		return "key:" + principal.ID
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
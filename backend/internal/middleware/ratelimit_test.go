@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLimitString(t *testing.T) {
+	if got := (Limit{60, time.Minute}).String(); got != "60/1m0s" {
+		t.Errorf("String = %q, want 60/1m0s", got)
+	}
+}
+
+func TestBucketTake(t *testing.T) {
+	limit := Limit{Requests: 3, Period: 3 * time.Second}
+	start := time.Unix(1000, 0)
+	var bucket Bucket
+
+	// A new bucket allows a burst of the full limit
+	for i := 2; i >= 0; i-- {
+		result := bucket.Take(limit, start)
+		if !result.Allowed || result.Remaining != i {
+			t.Fatalf("burst: %+v, want allowed with %d remaining", result, i)
+		}
+	}
+	if result := bucket.Take(limit, start); result.Allowed || result.RetryAfter != time.Second || result.Reset != 3*time.Second {
+		t.Errorf("empty bucket: %+v, want denied, retry after 1s, reset in 3s", result)
+	}
+
+	// Tokens refill continuously at one per second
+	half := start.Add(500 * time.Millisecond)
+	if result := bucket.Take(limit, half); result.Allowed || result.RetryAfter != 500*time.Millisecond {
+		t.Errorf("after 500ms: %+v, want denied, retry after 500ms", result)
+	}
+	if result := bucket.Take(limit, start.Add(time.Second)); !result.Allowed || result.Remaining != 0 {
+		t.Errorf("after 1s: %+v, want one token", result)
+	}
+
+	// and never above the limit
+	if result := bucket.Take(limit, start.Add(time.Hour)); !result.Allowed || result.Remaining != 2 || result.Reset != time.Second {
+		t.Errorf("after an hour: %+v, want a full bucket less one", result)
+	}
+}
+
+func TestBucketPeek(t *testing.T) {
+	limit := Limit{Requests: 1, Period: time.Minute}
+	now := time.Unix(1000, 0)
+	var bucket Bucket
+
+	for i := 0; i < 3; i++ {
+		if result := bucket.Peek(limit, now); !result.Allowed || result.Remaining != 1 {
+			t.Fatalf("peek %d: %+v, want a token left untouched", i, result)
+		}
+	}
+	bucket.Take(limit, now)
+	if result := bucket.Peek(limit, now.Add(30*time.Second)); result.Allowed || result.RetryAfter != 30*time.Second {
+		t.Errorf("peek after take: %+v, want denied, retry after 30s", result)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := NewMemoryStore()
+	router := gin.New()
+	router.Use(RateLimit(RateLimitConfig{
+		Default: Limit{Requests: 2, Period: time.Minute},
+		Routes:  map[string]Limit{"POST /process": {Requests: 1, Period: 10 * time.Second}},
+		Store:   store,
+	}))
+	router.GET("/read", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/process", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	do := func(method, path, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodGet, "/read", "192.0.2.1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("first read: status %d", w.Code)
+	}
+	headers := map[string]string{
+		"RateLimit-Limit":     "2",
+		"RateLimit-Remaining": "1",
+		"RateLimit-Reset":     "30",
+		"RateLimit-Policy":    "2;w=60",
+	}
+	for name, want := range headers {
+		if got := w.Header().Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	do(http.MethodGet, "/read", "192.0.2.1")
+	w = do(http.MethodGet, "/read", "192.0.2.1")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "30" || w.Header().Get("RateLimit-Reset") != "60" {
+		t.Errorf("third read: status %d, Retry-After %q, RateLimit-Reset %q; want 429, 30, 60",
+			w.Code, w.Header().Get("Retry-After"), w.Header().Get("RateLimit-Reset"))
+	}
+
+	// Routes with their own limit and other clients have their own buckets
+	if w := do(http.MethodPost, "/process", "192.0.2.1"); w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "1" {
+		t.Errorf("process: status %d, limit %q; want 200 under the process limit", w.Code, w.Header().Get("RateLimit-Limit"))
+	}
+	if w := do(http.MethodPost, "/process", "192.0.2.1"); w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "10" {
+		t.Errorf("second process: status %d, Retry-After %q; want 429, 10", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := do(http.MethodGet, "/read", "192.0.2.2"); w.Code != http.StatusOK {
+		t.Errorf("another client: status %d, want 200", w.Code)
+	}
+
+	if result, _ := store.Peek(context.Background(), "default|ip:192.0.2.1", Limit{Requests: 2, Period: time.Minute}, time.Now()); result.Allowed {
+		t.Errorf("Peek of the exhausted bucket = %+v, want denied", result)
+	}
+}
@@ -12,6 +12,68 @@ type Tool struct {
 	Features    []string  `json:"features" db:"features"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	// SettingsSchema describes the settings the tool accepts; it is only
+	// included when a single tool is requested
+	SettingsSchema *SettingsSchema `json:"settingsSchema,omitempty" db:"-"`
+}
+
+// PipelineStep represents a single tool invocation in a pipeline
+type PipelineStep struct {
+	ToolID   string                 `json:"toolId"`
+	Settings map[string]interface{} `json:"settings,omitempty"`
+}
+
+// PipelineRequest represents a request to run input through several tools
+type PipelineRequest struct {
+	Input string         `json:"input"`
+	Steps []PipelineStep `json:"steps"`
+}
+
+// PipelineStepResult records the outcome of one pipeline step
+type PipelineStepResult struct {
+	ToolID     string                 `json:"toolId"`
+	Output     string                 `json:"output"`
+	Error      string                 `json:"error,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	DurationMs float64                `json:"durationMs"`
+}
+
+// Recipe represents a saved, named pipeline
+type Recipe struct {
+	ID          string         `json:"id" db:"id"`
+	Name        string         `json:"name" db:"name"`
+	Description string         `json:"description" db:"description"`
+	Steps       []PipelineStep `json:"steps" db:"steps"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// RecipeRequest represents a request to create or update a recipe
+type RecipeRequest struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Steps       []PipelineStep `json:"steps"`
+}
+
+// SettingsSchema describes the settings accepted by a tool
+type SettingsSchema struct {
+	Type       string                     `json:"type"`
+	Properties map[string]SettingProperty `json:"properties,omitempty"`
+}
+
+// SettingProperty describes a single tool setting. Type is "string",
+// "boolean", "integer" or "number"; Minimum and Maximum bound numbers,
+// MinLength and MaxLength (when positive) the length of strings.
+type SettingProperty struct {
+	Type        string        `json:"type"`
+	Description string        `json:"description,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Minimum     *float64      `json:"minimum,omitempty"`
+	Maximum     *float64      `json:"maximum,omitempty"`
+	MinLength   int           `json:"minLength,omitempty"`
+	MaxLength   int           `json:"maxLength,omitempty"`
 }
 
 // ToolRequest represents a request to process a tool
@@ -39,6 +101,7 @@ type UserSettings struct {
 // ToolHistoryEntry represents a tool usage history entry
 type ToolHistoryEntry struct {
 	ID        int                    `json:"id" db:"id"`
+	UserID    string                 `json:"-" db:"user_id"`
 	ToolID    string                 `json:"tool_id" db:"tool_id"`
 	Input     string                 `json:"input" db:"input"`
 	Output    string                 `json:"output" db:"output"`
@@ -46,16 +109,139 @@ type ToolHistoryEntry struct {
 	CreatedAt time.Time              `json:"created_at" db:"created_at"`
 }
 
-// ErrorResponse represents an error response
+// ProtoSchema represents an uploaded protobuf schema
+type ProtoSchema struct {
+	ID         string    `json:"id" db:"id"`
+	Name       string    `json:"name" db:"name"`
+	Kind       string    `json:"kind" db:"kind"`
+	Descriptor []byte    `json:"-" db:"descriptor"`
+	Files      []string  `json:"files"`
+	Messages   []string  `json:"messages"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// SchemaDecodeRequest represents a request to convert a protobuf payload
+// using a stored schema
+type SchemaDecodeRequest struct {
+	Input        string `json:"input"`
+	MessageType  string `json:"message_type"`
+	InputFormat  string `json:"input_format,omitempty"`
+	OutputFormat string `json:"output_format,omitempty"`
+	Encoding     string `json:"encoding,omitempty"`
+}
+
+// HistoryPage represents a paginated list of tool history entries
+type HistoryPage struct {
+	Entries []ToolHistoryEntry `json:"entries"`
+	Total   int                `json:"total"`
+	Page    int                `json:"page"`
+	Limit   int                `json:"limit"`
+}
+
+// HistorySearchResult represents a full-text search hit in the tool history.
+// Snippets mark matched terms with <mark> and </mark>.
+type HistorySearchResult struct {
+	ToolHistoryEntry
+	Rank          float64 `json:"rank"`
+	InputSnippet  string  `json:"input_snippet"`
+	OutputSnippet string  `json:"output_snippet"`
+}
+
+// HistorySearchPage represents a paginated list of history search results
+type HistorySearchPage struct {
+	Results []HistorySearchResult `json:"results"`
+	Total   int                   `json:"total"`
+	Page    int                   `json:"page"`
+	Limit   int                   `json:"limit"`
+}
+
+// APIKey represents an issued API key. The key itself is only returned once,
+// when it is created.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// APIKeyRequest represents a request to issue an API key
+type APIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// CreatedAPIKey represents a newly issued API key including its secret
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// API key scopes. tools:write changes or deletes data shared by every user,
+// such as recipes and schemas, and is never granted to anonymous callers.
+const (
+	ScopeToolsRead    = "tools:read"
+	ScopeToolsProcess = "tools:process"
+	ScopeToolsWrite   = "tools:write"
+	ScopeAdmin        = "admin"
+)
+
+// Scopes lists every scope that can be granted to an API key
+var Scopes = []string{ScopeToolsRead, ScopeToolsProcess, ScopeToolsWrite, ScopeAdmin}
+
+// Principal identifies the caller of a request
+type Principal struct {
+	// Type is "user" for users logged in with OpenID Connect, "api_key" for
+	// API key holders and "anonymous" otherwise
+	Type   string   `json:"type"`
+	ID     string   `json:"id,omitempty"`
+	Name   string   `json:"name,omitempty"`
+	Scopes []string `json:"scopes"`
+}
+
+// HasScope reports whether the principal was granted scope. The admin scope
+// grants every other scope.
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrorResponse represents an error response. Details is a message, or an
+// object of fields for errors that have structured context.
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Code    string `json:"code,omitempty"`
-	Details string `json:"details,omitempty"`
+	Error   string      `json:"error"`
+	Code    string      `json:"code,omitempty"`
+	Details interface{} `json:"details,omitempty"`
 }
 
-// HealthResponse represents a health check response
+// HealthResponse represents a liveness check response
 type HealthResponse struct {
 	Status    string `json:"status"`
 	Timestamp string `json:"timestamp"`
 	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+}
+
+// ReadinessResponse represents a readiness check response with the status of
+// every dependency
+type ReadinessResponse struct {
+	Status    string                     `json:"status"`
+	Timestamp string                     `json:"timestamp"`
+	Version   string                     `json:"version"`
+	Checks    map[string]ComponentStatus `json:"checks"`
+}
+
+// ComponentStatus represents the result of checking one dependency
+type ComponentStatus struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Details   string  `json:"details,omitempty"`
+	Error     string  `json:"error,omitempty"`
 }
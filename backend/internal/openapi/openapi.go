@@ -0,0 +1,363 @@
+// Package openapi builds the OpenAPI 3 description of the API from the
+// routes registered with gin and a table documenting each of them.
+// Request and response schemas are derived from Go types by reflection.
+package openapi
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+// Version is the OpenAPI version of generated documents
+const Version = "3.0.3"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Tags       []Tag                 `json:"tags,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []map[string][]string `json:"security,omitempty"`
+}
+
+// Info holds the API metadata
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Tag groups operations
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of one path, keyed by lower-case method
+type PathItem map[string]*Operation
+
+// Operation describes one route
+type Operation struct {
+	Tags        []string            `json:"tags,omitempty"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	OperationID string              `json:"operationId,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	Deprecated  bool                `json:"deprecated,omitempty"`
+
+	// Scope is the API key scope the route requires
+	Scope string `json:"x-required-scope,omitempty"`
+}
+
+// Parameter describes a path or query parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the body of a request
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes a response of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body in one content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the reusable schemas and security schemes
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes a way to authenticate
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	In          string `json:"in,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Route documents a registered route. Request and response bodies are given
+// as values of their Go type and described as JSON, unless they are a Body.
+type Route struct {
+	Summary     string
+	Description string
+	Tag         string
+	// Scope is the API key scope enforced on the route, if any
+	Scope string
+	// Path holds the schemas of path parameters, strings by default; the
+	// parameters themselves are derived from the route pattern
+	Path map[string]*Schema
+	// Query lists the query parameters
+	Query []Parameter
+	// Request is the request body, nil for none
+	Request interface{}
+	// Responses maps status codes to response bodies, nil for none
+	Responses map[int]interface{}
+	// Deprecated marks routes kept for existing clients
+	Deprecated bool
+}
+
+// Body is a request or response body that is not JSON
+type Body struct {
+	ContentType string
+	Schema      *Schema
+}
+
+// Generator builds a document, collecting the schemas of the Go types it
+// meets as components
+type Generator struct {
+	doc *Document
+}
+
+// NewGenerator creates a generator for a document with the given info
+func NewGenerator(info Info) *Generator {
+	return &Generator{doc: &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+		},
+	}}
+}
+
+// Document returns the document built so far
+func (g *Generator) Document() *Document {
+	return g.doc
+}
+
+// AddTag describes a tag used by operations
+func (g *Generator) AddTag(name, description string) {
+	g.doc.Tags = append(g.doc.Tags, Tag{Name: name, Description: description})
+}
+
+// AddSecurityScheme declares a security scheme accepted by every operation,
+// as an alternative to the schemes added before it
+func (g *Generator) AddSecurityScheme(name string, scheme SecurityScheme) {
+	if g.doc.Components.SecuritySchemes == nil {
+		g.doc.Components.SecuritySchemes = map[string]SecurityScheme{}
+	}
+	g.doc.Components.SecuritySchemes[name] = scheme
+	g.doc.Security = append(g.doc.Security, map[string][]string{name: {}})
+}
+
+// AllowAnonymous makes credentials optional for every operation
+func (g *Generator) AllowAnonymous() {
+	g.doc.Security = append(g.doc.Security, map[string][]string{})
+}
+
+// AddSchema registers a named component schema and returns a reference to it
+func (g *Generator) AddSchema(name string, schema *Schema) *Schema {
+	g.doc.Components.Schemas[name] = schema
+	return Ref(name)
+}
+
+// Add documents the route registered for method and path, a gin pattern
+func (g *Generator) Add(method, path string, route Route) {
+	openAPIPath, params := convertPath(path)
+
+	operation := &Operation{
+		Summary:     route.Summary,
+		Description: route.Description,
+		OperationID: operationID(method, openAPIPath),
+		Responses:   map[string]Response{},
+		Deprecated:  route.Deprecated,
+		Scope:       route.Scope,
+	}
+	if route.Tag != "" {
+		operation.Tags = []string{route.Tag}
+	}
+
+	for _, name := range params {
+		schema, ok := route.Path[name]
+		if !ok {
+			schema = &Schema{Type: "string"}
+		}
+		operation.Parameters = append(operation.Parameters, Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   schema,
+		})
+	}
+	for _, param := range route.Query {
+		param.In = "query"
+		operation.Parameters = append(operation.Parameters, param)
+	}
+
+	if route.Request != nil {
+		contentType, schema := g.body(route.Request)
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{contentType: {Schema: schema}},
+		}
+	}
+
+	for status, body := range route.Responses {
+		response := Response{Description: http.StatusText(status)}
+		if body != nil {
+			contentType, schema := g.body(body)
+			response.Content = map[string]MediaType{contentType: {Schema: schema}}
+		}
+		operation.Responses[strconv.Itoa(status)] = response
+	}
+
+	if route.Scope != "" {
+		operation.Description = strings.TrimSpace(operation.Description + "\n\nRequires the `" + route.Scope + "` scope.")
+		operation.Responses["401"] = g.errorResponse(http.StatusUnauthorized)
+		operation.Responses["403"] = g.errorResponse(http.StatusForbidden)
+	}
+	if _, ok := operation.Responses["default"]; !ok && strings.HasPrefix(path, "/api/") {
+		operation.Responses["default"] = Response{
+			Description: "Error",
+			Content:     map[string]MediaType{"application/json": {Schema: g.Schema(models.ErrorResponse{})}},
+		}
+	}
+
+	item, ok := g.doc.Paths[openAPIPath]
+	if !ok {
+		item = PathItem{}
+		g.doc.Paths[openAPIPath] = item
+	}
+	item[strings.ToLower(method)] = operation
+}
+
+func (g *Generator) errorResponse(status int) Response {
+	return Response{
+		Description: http.StatusText(status),
+		Content:     map[string]MediaType{"application/json": {Schema: g.Schema(models.ErrorResponse{})}},
+	}
+}
+
+func (g *Generator) body(value interface{}) (string, *Schema) {
+	if body, ok := value.(Body); ok {
+		return body.ContentType, body.Schema
+	}
+	return "application/json", g.Schema(value)
+}
+
+// Drift compares the routes registered with gin against the documented
+// ones, keyed by method and gin path such as "GET /api/tools/:toolId". It
+// returns the registered routes that are not documented and the documented
+// routes that are not registered, each sorted.
+func Drift(registered gin.RoutesInfo, documented map[string]Route) (undocumented, unregistered []string) {
+	seen := map[string]bool{}
+	for _, route := range registered {
+		key := route.Method + " " + route.Path
+		seen[key] = true
+		if _, ok := documented[key]; !ok {
+			undocumented = append(undocumented, key)
+		}
+	}
+	for key := range documented {
+		if !seen[key] {
+			unregistered = append(unregistered, key)
+		}
+	}
+
+	sort.Strings(undocumented)
+	sort.Strings(unregistered)
+	return undocumented, unregistered
+}
+
+// convertPath turns a gin pattern into an OpenAPI path, returning the names
+// of its parameters
+func convertPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			name := segment[1:]
+			params = append(params, name)
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID derives a stable identifier such as getApiToolsToolId
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '{' || r == '}' || r == '-' || r == '.' || r == '_'
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// UnresolvedRefs returns the schema references that name no component,
+// sorted
+func (d *Document) UnresolvedRefs() []string {
+	missing := map[string]bool{}
+	var visit func(schema *Schema)
+	visit = func(schema *Schema) {
+		if schema == nil {
+			return
+		}
+		if name := strings.TrimPrefix(schema.Ref, "#/components/schemas/"); schema.Ref != "" {
+			if _, ok := d.Components.Schemas[name]; !ok {
+				missing[schema.Ref] = true
+			}
+		}
+		for _, property := range schema.Properties {
+			visit(property)
+		}
+		visit(schema.Items)
+		if additional, ok := schema.AdditionalProperties.(*Schema); ok {
+			visit(additional)
+		}
+	}
+
+	for _, schema := range d.Components.Schemas {
+		visit(schema)
+	}
+	for _, item := range d.Paths {
+		for _, operation := range item {
+			for _, param := range operation.Parameters {
+				visit(param.Schema)
+			}
+			if operation.RequestBody != nil {
+				for _, media := range operation.RequestBody.Content {
+					visit(media.Schema)
+				}
+			}
+			for _, response := range operation.Responses {
+				for _, media := range response.Content {
+					visit(media.Schema)
+				}
+			}
+		}
+	}
+
+	refs := make([]string, 0, len(missing))
+	for ref := range missing {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	return refs
+}
@@ -0,0 +1,49 @@
+package openapi
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// swaggerUIVersion pins the swagger-ui-dist release the page loads
+const swaggerUIVersion = "5.17.14"
+
+var swaggerUIPage = template.Must(template.New("swagger-ui").Parse(`<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.Title}}</title>
+  <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/swagger-ui-dist@{{.Version}}/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://cdn.jsdelivr.net/npm/swagger-ui-dist@{{.Version}}/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({
+      url: {{.SpecURL}},
+      dom_id: "#swagger-ui",
+      deepLinking: true,
+      withCredentials: true
+    });
+  </script>
+</body>
+</html>
+`))
+
+// SwaggerUI serves a Swagger UI page for the document at specURL. The page
+// is part of the binary; the Swagger UI scripts are loaded from a CDN.
+func SwaggerUI(title, specURL string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Header("Cache-Control", "no-cache")
+		c.Status(http.StatusOK)
+		swaggerUIPage.Execute(c.Writer, map[string]string{
+			"Title":   title,
+			"Version": swaggerUIVersion,
+			"SpecURL": specURL,
+		})
+	}
+}
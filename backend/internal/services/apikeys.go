@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"web-tools-platform/backend/internal/database"
+	"web-tools-platform/backend/internal/models"
+)
+
+const (
+	// apiKeyPrefix marks secrets issued by this service so they are easy to
+	// recognise in scripts and secret scanners
+	apiKeyPrefix = "wtp_"
+	// apiKeyBytes is the amount of randomness in a key
+	apiKeyBytes = 24
+	// apiKeyDisplayLength is the number of leading characters stored in the
+	// clear to tell keys apart
+	apiKeyDisplayLength = 12
+	// maxAPIKeyNameLength bounds API key names
+	maxAPIKeyNameLength = 100
+	// apiKeyTouchInterval limits how often last_used_at is written
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	// ErrInvalidAPIKey is returned when an API key request fails validation
+	ErrInvalidAPIKey = errors.New("invalid API key request")
+	// ErrInvalidCredentials is returned when a presented API key is unknown
+	// or revoked
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// GetAPIKeys returns all issued API keys
+func (s *Service) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return s.db.GetAPIKeys(ctx)
+}
+
+// CreateAPIKey issues a new API key. The returned secret is not stored and
+// cannot be retrieved again.
+func (s *Service) CreateAPIKey(ctx context.Context, request models.APIKeyRequest) (*models.CreatedAPIKey, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidAPIKey)
+	}
+	if len(name) > maxAPIKeyNameLength {
+		return nil, fmt.Errorf("%w: name must be at most %d characters", ErrInvalidAPIKey, maxAPIKeyNameLength)
+	}
+
+	if len(request.Scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKey)
+	}
+	scopes := []string{}
+	for _, scope := range request.Scopes {
+		if !containsString(models.Scopes, scope) {
+			return nil, fmt.Errorf("%w: unknown scope %q, expected one of %s",
+				ErrInvalidAPIKey, scope, strings.Join(models.Scopes, ", "))
+		}
+		if !containsString(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	secret, err := newAPIKeySecret()
+	if err != nil {
+		return nil, err
+	}
+
+	key := models.APIKey{
+		ID:     uuid.New().String(),
+		Name:   name,
+		Prefix: secret[:apiKeyDisplayLength],
+		Scopes: scopes,
+	}
+	if err := s.db.CreateAPIKey(ctx, &key, hashAPIKey(secret)); err != nil {
+		return nil, err
+	}
+
+	return &models.CreatedAPIKey{APIKey: key, Key: secret}, nil
+}
+
+// RevokeAPIKey revokes an API key so it can no longer be used
+func (s *Service) RevokeAPIKey(ctx context.Context, id string) error {
+	return s.db.RevokeAPIKey(ctx, id)
+}
+
+// Authenticate returns the principal for an API key secret. It returns
+// ErrInvalidCredentials if the key is unknown or revoked.
+func (s *Service) Authenticate(ctx context.Context, secret string) (*models.Principal, error) {
+	key, err := s.db.GetAPIKeyByHash(ctx, hashAPIKey(secret))
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if key.LastUsedAt == nil || time.Since(*key.LastUsedAt) > apiKeyTouchInterval {
+		if err := s.db.TouchAPIKey(ctx, key.ID); err != nil {
+			logrus.WithError(err).WithField("api_key_id", key.ID).Warn("Failed to record API key use")
+		}
+	}
+
+	return &models.Principal{
+		Type:   "api_key",
+		ID:     key.ID,
+		Name:   key.Name,
+		Scopes: key.Scopes,
+	}, nil
+}
+
+// newAPIKeySecret generates a random API key secret
+func newAPIKeySecret() (string, error) {
+	b := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + hex.EncodeToString(b), nil
+}
+
+// hashAPIKey returns the hex encoded SHA-256 hash stored for a secret. Keys
+// are long random strings, so a fast hash is sufficient.
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"web-tools-platform/backend/internal/database"
+	"web-tools-platform/backend/internal/models"
+)
+
+// GetHistory returns a page of tool history entries
+func (s *Service) GetHistory(ctx context.Context, filter database.HistoryFilter) ([]models.ToolHistoryEntry, int, error) {
+	return s.db.GetHistory(ctx, filter)
+}
+
+// GetHistoryEntry returns a single history entry of a user
+func (s *Service) GetHistoryEntry(ctx context.Context, userID string, id int) (*models.ToolHistoryEntry, error) {
+	return s.db.GetHistoryEntry(ctx, userID, id)
+}
+
+// DeleteHistoryEntry removes a single history entry of a user
+func (s *Service) DeleteHistoryEntry(ctx context.Context, userID string, id int) error {
+	return s.db.DeleteHistoryEntry(ctx, userID, id)
+}
+
+// ClearHistory removes all history entries matching the filter
+func (s *Service) ClearHistory(ctx context.Context, filter database.HistoryFilter) (int64, error) {
+	return s.db.ClearHistory(ctx, filter)
+}
+
+// ErrInvalidSearch is returned when a history search query has no terms
+var ErrInvalidSearch = errors.New("invalid search query")
+
+// SearchHistory finds history entries whose input or output contains all the
+// words of q. The last word also matches as a prefix so partial words work
+// while typing.
+func (s *Service) SearchHistory(ctx context.Context, q string, filter database.HistoryFilter) ([]models.HistorySearchResult, int, error) {
+	words := strings.Fields(q)
+	if len(words) == 0 {
+		return nil, 0, ErrInvalidSearch
+	}
+
+	return s.db.SearchHistory(ctx, words, filter)
+}
+
+// historyEnabled reports whether tool invocations by userID should be
+// recorded, following their toolHistory setting
+func (s *Service) historyEnabled(ctx context.Context, userID string) bool {
+	settings, err := s.GetSettings(ctx, userID)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to load settings for tool history")
+		return false
+	}
+
+	enabled, _ := settings["toolHistory"].(bool)
+	return enabled
+}
+
+// recordHistory stores a successful tool invocation of a known caller.
+// Failures are logged rather than returned so that history never breaks
+// processing.
+func (s *Service) recordHistory(ctx context.Context, userID, toolID string, request models.ToolRequest, response *models.ToolResponse) {
+	if userID == "" || response == nil || response.Error != "" || !s.historyEnabled(ctx, userID) {
+		return
+	}
+
+	entry := &models.ToolHistoryEntry{
+		UserID:   userID,
+		ToolID:   toolID,
+		Input:    request.Input,
+		Output:   response.Output,
+		Settings: request.Settings,
+	}
+	if err := s.db.CreateHistoryEntry(ctx, entry); err != nil {
+		logrus.WithError(err).WithField("tool_id", toolID).Warn("Failed to record tool history")
+	}
+}
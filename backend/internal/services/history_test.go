@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"web-tools-platform/backend/internal/database"
+	"web-tools-platform/backend/internal/models"
+)
+
+func TestProcessToolRecordsHistory(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	encode := models.ToolRequest{Input: "hello", Settings: map[string]interface{}{"mode": "encode"}}
+	if _, err := s.ProcessTool(ctx, "alice", "base64", encode); err != nil {
+		t.Fatalf("ProcessTool: %v", err)
+	}
+
+	// Failed invocations are not recorded
+	invalid := models.ToolRequest{Input: "not base64!", Settings: map[string]interface{}{"mode": "decode"}}
+	if _, err := s.ProcessTool(ctx, "alice", "base64", invalid); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("ProcessTool of invalid input = %v, want ErrInvalidInput", err)
+	}
+	if _, err := s.ProcessTool(ctx, "alice", "missing", encode); !errors.Is(err, ErrToolNotFound) {
+		t.Fatalf("ProcessTool of an unknown tool = %v, want ErrToolNotFound", err)
+	}
+
+	entries, total, err := s.GetHistory(ctx, database.HistoryFilter{UserID: "alice"})
+	if err != nil {
+		t.Fatalf("GetHistory: %v", err)
+	}
+	if total != 1 {
+		t.Fatalf("%d entries recorded, want only the successful invocation", total)
+	}
+	if entry := entries[0]; entry.ToolID != "base64" || entry.Input != "hello" || entry.Output != "aGVsbG8=" || entry.Settings["mode"] != "encode" {
+		t.Errorf("entry = %+v, want the base64 invocation", entry)
+	}
+}
+
+func TestProcessToolFollowsHistorySetting(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	if _, err := s.PatchSettings(ctx, "alice", map[string]interface{}{"toolHistory": false}); err != nil {
+		t.Fatalf("PatchSettings: %v", err)
+	}
+
+	request := models.ToolRequest{Input: "hello", Settings: map[string]interface{}{"mode": "encode"}}
+	for _, user := range []string{"alice", "bob"} {
+		if _, err := s.ProcessTool(ctx, user, "base64", request); err != nil {
+			t.Fatalf("ProcessTool for %s: %v", user, err)
+		}
+	}
+
+	if _, total, _ := s.GetHistory(ctx, database.HistoryFilter{UserID: "alice"}); total != 0 {
+		t.Errorf("%d entries for alice, want none with toolHistory off", total)
+	}
+	if _, total, _ := s.GetHistory(ctx, database.HistoryFilter{UserID: "bob"}); total != 1 {
+		t.Errorf("%d entries for bob, want history on by default", total)
+	}
+
+	deleted, err := s.ClearHistory(ctx, database.HistoryFilter{UserID: "bob"})
+	if err != nil || deleted != 1 {
+		t.Errorf("ClearHistory = %d, %v; want 1", deleted, err)
+	}
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+// MaxPipelineSteps limits the number of steps in a single pipeline
+const MaxPipelineSteps = 20
+
+// ErrInvalidPipeline is returned when a pipeline cannot be run as requested
+var ErrInvalidPipeline = errors.New("invalid pipeline")
+
+// ProcessPipeline runs the input through each step in order, feeding the
+// output of every step into the next one. Processing stops at the first step
+// that reports an error, or that is still running or yet to start when the
+// pipeline timeout passes; per-step results are returned in
+// Metadata["steps"].
+func (s *Service) ProcessPipeline(ctx context.Context, request models.PipelineRequest) (*models.ToolResponse, error) {
+	if err := s.validatePipeline(request.Steps); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPipeline, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.limits.PipelineTimeout)
+	defer cancel()
+
+	results := make([]models.PipelineStepResult, 0, len(request.Steps))
+	response := &models.ToolResponse{}
+	input := request.Input
+	started := time.Now()
+
+	for i, step := range request.Steps {
+		stepStarted := time.Now()
+		var stepResponse *models.ToolResponse
+		err := ctx.Err()
+		if err == nil {
+			stepResponse, err = s.processTool(ctx, step.ToolID, models.ToolRequest{
+				Input:    input,
+				Settings: step.Settings,
+			})
+		}
+		timedOut := errors.Is(err, ErrToolTimeout) || errors.Is(err, context.DeadlineExceeded)
+		if timedOut && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = pipelineTimeout(s.limits.PipelineTimeout)
+		}
+		var toolErr *ToolError
+		if errors.As(err, &toolErr) {
+			// Reported with the partial results like any failed step
+			stepResponse, err = &models.ToolResponse{Error: toolErr.Message, Metadata: toolErr.Details}, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("step %d (%s): %w", i+1, step.ToolID, err)
+		}
+
+		results = append(results, models.PipelineStepResult{
+			ToolID:     step.ToolID,
+			Output:     stepResponse.Output,
+			Error:      stepResponse.Error,
+			Metadata:   stepResponse.Metadata,
+			DurationMs: durationMs(time.Since(stepStarted)),
+		})
+
+		if stepResponse.Error != "" {
+			response.Error = fmt.Sprintf("Step %d (%s): %s", i+1, step.ToolID, stepResponse.Error)
+			break
+		}
+
+		input = stepResponse.Output
+		response.Output = stepResponse.Output
+	}
+
+	response.Metadata = map[string]interface{}{
+		"steps":      results,
+		"durationMs": durationMs(time.Since(started)),
+	}
+
+	return response, nil
+}
+
+// validatePipeline checks the steps before any of them is run
+func (s *Service) validatePipeline(steps []models.PipelineStep) error {
+	if len(steps) == 0 {
+		return errors.New("at least one step is required")
+	}
+	if len(steps) > MaxPipelineSteps {
+		return fmt.Errorf("at most %d steps are allowed", MaxPipelineSteps)
+	}
+
+	for i, step := range steps {
+		tool, ok := s.registry.Get(step.ToolID)
+		if !ok {
+			return fmt.Errorf("step %d: unsupported tool: %s", i+1, step.ToolID)
+		}
+		if _, err := validateSettings(tool.SettingsSchema(), step.Settings); err != nil {
+			return fmt.Errorf("step %d (%s): %v", i+1, step.ToolID, err)
+		}
+	}
+
+	return nil
+}
+
+// durationMs converts d to fractional milliseconds
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+func TestProcessPipeline(t *testing.T) {
+	s := &Service{registry: defaultRegistry, limits: DefaultLimits}
+
+	response, err := s.ProcessPipeline(context.Background(), models.PipelineRequest{
+		Input: "hello",
+		Steps: []models.PipelineStep{
+			{ToolID: "base64", Settings: map[string]interface{}{"mode": "encode"}},
+			{ToolID: "base64", Settings: map[string]interface{}{"mode": "decode"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ProcessPipeline: %v", err)
+	}
+	if response.Output != "hello" || response.Error != "" {
+		t.Errorf("response = %+v, want output hello", response)
+	}
+
+	results := response.Metadata["steps"].([]models.PipelineStepResult)
+	if len(results) != 2 || results[0].Output != "aGVsbG8=" {
+		t.Errorf("steps = %+v, want the encoded input from the first step", results)
+	}
+}
+
+func TestProcessPipelineStopsAtFailedStep(t *testing.T) {
+	s := &Service{registry: defaultRegistry, limits: DefaultLimits}
+
+	response, err := s.ProcessPipeline(context.Background(), models.PipelineRequest{
+		Input: "not base64!",
+		Steps: []models.PipelineStep{
+			{ToolID: "base64", Settings: map[string]interface{}{"mode": "decode"}},
+			{ToolID: "base64", Settings: map[string]interface{}{"mode": "encode"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ProcessPipeline: %v", err)
+	}
+	if !strings.HasPrefix(response.Error, "Step 1 (base64): ") {
+		t.Errorf("error = %q, want it to name the first step", response.Error)
+	}
+	if results := response.Metadata["steps"].([]models.PipelineStepResult); len(results) != 1 {
+		t.Errorf("%d step results, want the pipeline stopped after the first", len(results))
+	}
+}
+
+func TestProcessPipelineValidation(t *testing.T) {
+	s := &Service{registry: defaultRegistry, limits: DefaultLimits}
+
+	tooMany := make([]models.PipelineStep, MaxPipelineSteps+1)
+	for i := range tooMany {
+		tooMany[i] = models.PipelineStep{ToolID: "base64"}
+	}
+
+	tests := map[string][]models.PipelineStep{
+		"no steps":     nil,
+		"too many":     tooMany,
+		"unknown tool": {{ToolID: "base64"}, {ToolID: "missing"}},
+	}
+	for name, steps := range tests {
+		_, err := s.ProcessPipeline(context.Background(), models.PipelineRequest{Input: "x", Steps: steps})
+		if !errors.Is(err, ErrInvalidPipeline) {
+			t.Errorf("%s: got %v, want ErrInvalidPipeline", name, err)
+		}
+	}
+}
+
+// sleepTool echoes its input after a delay
+type sleepTool struct {
+	delay time.Duration
+}
+
+func (sleepTool) ID() string { return "sleep" }
+
+func (sleepTool) Metadata() models.Tool { return models.Tool{ID: "sleep", Name: "Sleep"} }
+
+func (sleepTool) SettingsSchema() models.SettingsSchema {
+	return models.SettingsSchema{Type: "object"}
+}
+
+func (t sleepTool) Process(request models.ToolRequest) (*models.ToolResponse, error) {
+	time.Sleep(t.delay)
+	return &models.ToolResponse{Output: request.Input}, nil
+}
+
+func TestProcessPipelineTimeout(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register(sleepTool{delay: 40 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{registry: registry, limits: Limits{
+		MaxInputBytes:   1 << 10,
+		Timeout:         time.Second,
+		PipelineTimeout: 100 * time.Millisecond,
+	}}
+
+	steps := make([]models.PipelineStep, 10)
+	for i := range steps {
+		steps[i] = models.PipelineStep{ToolID: "sleep"}
+	}
+
+	started := time.Now()
+	response, err := s.ProcessPipeline(context.Background(), models.PipelineRequest{Input: "x", Steps: steps})
+	if err != nil {
+		t.Fatalf("ProcessPipeline: %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+		t.Errorf("pipeline ran for %s, want it stopped near its 100ms timeout", elapsed)
+	}
+
+	if !strings.Contains(response.Error, "Pipeline took longer than 100ms") {
+		t.Errorf("error = %q, want the pipeline timeout", response.Error)
+	}
+	results := response.Metadata["steps"].([]models.PipelineStepResult)
+	if len(results) >= len(steps) {
+		t.Fatalf("%d step results, want the pipeline stopped early", len(results))
+	}
+	last := results[len(results)-1]
+	if !strings.Contains(last.Error, "Pipeline took longer than") {
+		t.Errorf("last step error = %q, want the pipeline timeout", last.Error)
+	}
+	for _, result := range results[:len(results)-1] {
+		if result.Error != "" || result.Output != "x" {
+			t.Errorf("completed step = %+v, want its output", result)
+		}
+	}
+}
@@ -0,0 +1,655 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Schema-less parser and printer for the protobuf text format, as produced by
+// DebugString() and ShortDebugString(). Values are kept as their source tokens
+// so formatting never changes the meaning of a dump.
+
+// textSyntaxError describes a parse failure at a position in the input
+type textSyntaxError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *textSyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+type textTokenKind int
+
+const (
+	textEOF textTokenKind = iota
+	textIdent
+	textNumber
+	textString
+	textPunct
+)
+
+type textToken struct {
+	kind   textTokenKind
+	text   string
+	line   int
+	column int
+}
+
+func (t textToken) describe() string {
+	switch t.kind {
+	case textEOF:
+		return "end of input"
+	case textString:
+		return "string " + t.text
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// textLexer splits protobuf text format input into tokens
+type textLexer struct {
+	input  string
+	pos    int
+	line   int
+	column int
+}
+
+func newTextLexer(input string) *textLexer {
+	return &textLexer{input: input, line: 1, column: 1}
+}
+
+func (l *textLexer) errorf(line, column int, format string, args ...interface{}) error {
+	return &textSyntaxError{Line: line, Column: column, Message: fmt.Sprintf(format, args...)}
+}
+
+func (l *textLexer) peekByte(offset int) byte {
+	if l.pos+offset < len(l.input) {
+		return l.input[l.pos+offset]
+	}
+	return 0
+}
+
+func (l *textLexer) advance() byte {
+	c := l.input[l.pos]
+	l.pos++
+	if c == '\n' {
+		l.line++
+		l.column = 1
+	} else if c < 0x80 || c >= 0xC0 {
+		// Count columns in characters rather than UTF-8 continuation bytes
+		l.column++
+	}
+	return c
+}
+
+func (l *textLexer) skipSpaceAndComments() {
+	for l.pos < len(l.input) {
+		c := l.peekByte(0)
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v':
+			l.advance()
+		case c == '#':
+			for l.pos < len(l.input) && l.peekByte(0) != '\n' {
+				l.advance()
+			}
+		default:
+			return
+		}
+	}
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// next returns the next token in the input
+func (l *textLexer) next() (textToken, error) {
+	l.skipSpaceAndComments()
+
+	tok := textToken{line: l.line, column: l.column}
+	if l.pos >= len(l.input) {
+		tok.kind = textEOF
+		return tok, nil
+	}
+
+	start := l.pos
+	c := l.peekByte(0)
+
+	switch {
+	case isIdentStart(c):
+		for l.pos < len(l.input) && (isIdentStart(l.peekByte(0)) || isDigit(l.peekByte(0))) {
+			l.advance()
+		}
+		tok.kind = textIdent
+	case isDigit(c) || (c == '.' && isDigit(l.peekByte(1))):
+		for l.pos < len(l.input) {
+			c := l.peekByte(0)
+			if isIdentStart(c) || isDigit(c) || c == '.' {
+				l.advance()
+				if (c == 'e' || c == 'E') && !strings.HasPrefix(strings.ToLower(l.input[start:l.pos]), "0x") &&
+					(l.peekByte(0) == '+' || l.peekByte(0) == '-') {
+					l.advance()
+				}
+				continue
+			}
+			break
+		}
+		tok.kind = textNumber
+		if !isValidTextNumber(l.input[start:l.pos]) {
+			return tok, l.errorf(tok.line, tok.column, "invalid number %q", l.input[start:l.pos])
+		}
+	case c == '"' || c == '\'':
+		if err := l.scanString(c); err != nil {
+			return tok, err
+		}
+		tok.kind = textString
+	case strings.IndexByte(":{}<>[],;-/.", c) >= 0:
+		l.advance()
+		tok.kind = textPunct
+	default:
+		return tok, l.errorf(tok.line, tok.column, "unexpected character %q", rune(c))
+	}
+
+	tok.text = l.input[start:l.pos]
+	return tok, nil
+}
+
+// scanString consumes a quoted string literal, validating its escapes
+func (l *textLexer) scanString(quote byte) error {
+	line, column := l.line, l.column
+	l.advance()
+
+	for {
+		if l.pos >= len(l.input) || l.peekByte(0) == '\n' {
+			return l.errorf(line, column, "unterminated string")
+		}
+
+		c := l.advance()
+		if c == quote {
+			return nil
+		}
+		if c != '\\' {
+			continue
+		}
+
+		escLine, escColumn := l.line, l.column-1
+		if l.pos >= len(l.input) {
+			return l.errorf(line, column, "unterminated string")
+		}
+
+		e := l.advance()
+		switch {
+		case strings.IndexByte(`abfnrtv\'"?`, e) >= 0:
+		case e >= '0' && e <= '7':
+			for i := 0; i < 2 && l.peekByte(0) >= '0' && l.peekByte(0) <= '7'; i++ {
+				l.advance()
+			}
+		case e == 'x' || e == 'X':
+			if !l.scanHexDigits(1, 2) {
+				return l.errorf(escLine, escColumn, `invalid \x escape`)
+			}
+		case e == 'u':
+			if !l.scanHexDigits(4, 4) {
+				return l.errorf(escLine, escColumn, `invalid \u escape, expected 4 hex digits`)
+			}
+		case e == 'U':
+			if !l.scanHexDigits(8, 8) {
+				return l.errorf(escLine, escColumn, `invalid \U escape, expected 8 hex digits`)
+			}
+		default:
+			return l.errorf(escLine, escColumn, "invalid escape sequence \\%c", e)
+		}
+	}
+}
+
+func (l *textLexer) scanHexDigits(min, max int) bool {
+	n := 0
+	for n < max && isHexDigit(l.peekByte(0)) {
+		l.advance()
+		n++
+	}
+	return n >= min
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+var (
+	textDecIntPattern = regexp.MustCompile(`^(0|[1-9][0-9]*)$`)
+	textOctIntPattern = regexp.MustCompile(`^0[0-7]+$`)
+	textHexIntPattern = regexp.MustCompile(`^0[xX][0-9a-fA-F]+$`)
+	textFloatPattern  = regexp.MustCompile(`^(([0-9]+\.[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?|[0-9]+[eE][+-]?[0-9]+)[fF]?$|^(0|[1-9][0-9]*)[fF]$`)
+)
+
+func isValidTextNumber(s string) bool {
+	return textDecIntPattern.MatchString(s) ||
+		textOctIntPattern.MatchString(s) ||
+		textHexIntPattern.MatchString(s) ||
+		textFloatPattern.MatchString(s)
+}
+
+type textValueKind int
+
+const (
+	textScalarValue textValueKind = iota
+	textMessageValue
+	textListValue
+)
+
+// textValue is a parsed field value
+type textValue struct {
+	kind   textValueKind
+	scalar string
+	fields []textField
+	items  []textValue
+}
+
+// textField is a single "name: value" entry of a message
+type textField struct {
+	name  string
+	value textValue
+}
+
+// maxTextDepth bounds the nesting of messages and lists, like maxWireDepth
+// for the wire format; indented output grows with depth
+const maxTextDepth = 64
+
+// textParser builds a field tree from lexer tokens
+type textParser struct {
+	lexer *textLexer
+	tok   textToken
+	depth int
+}
+
+// parseTextFormat parses a protobuf text format message
+func parseTextFormat(input string) ([]textField, error) {
+	p := &textParser{lexer: newTextLexer(input)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	fields, err := p.parseFields("")
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != textEOF {
+		return nil, p.unexpected("field name")
+	}
+
+	return fields, nil
+}
+
+func (p *textParser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *textParser) isPunct(s string) bool {
+	return p.tok.kind == textPunct && p.tok.text == s
+}
+
+func (p *textParser) unexpected(expected string) error {
+	return &textSyntaxError{
+		Line:    p.tok.line,
+		Column:  p.tok.column,
+		Message: fmt.Sprintf("expected %s, found %s", expected, p.tok.describe()),
+	}
+}
+
+// enter descends into a nested message or list, failing past maxTextDepth;
+// callers pair it with leave
+func (p *textParser) enter() error {
+	if p.depth >= maxTextDepth {
+		return &textSyntaxError{
+			Line:    p.tok.line,
+			Column:  p.tok.column,
+			Message: fmt.Sprintf("nesting exceeds the maximum depth of %d", maxTextDepth),
+		}
+	}
+	p.depth++
+	return nil
+}
+
+func (p *textParser) leave() {
+	p.depth--
+}
+
+func (p *textParser) expect(s string) error {
+	if !p.isPunct(s) {
+		return p.unexpected(fmt.Sprintf("%q", s))
+	}
+	return p.advance()
+}
+
+// parseFields parses fields until the closing delimiter (or EOF when empty)
+func (p *textParser) parseFields(closing string) ([]textField, error) {
+	fields := []textField{}
+
+	for {
+		if closing == "" && p.tok.kind == textEOF {
+			return fields, nil
+		}
+		if closing != "" && p.isPunct(closing) {
+			return fields, nil
+		}
+		if p.tok.kind == textEOF {
+			return nil, p.unexpected(fmt.Sprintf("%q", closing))
+		}
+
+		field, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+
+		// Fields may optionally be separated by ';' or ','
+		if p.isPunct(";") || p.isPunct(",") {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func (p *textParser) parseField() (textField, error) {
+	name, err := p.parseFieldName()
+	if err != nil {
+		return textField{}, err
+	}
+
+	field := textField{name: name}
+
+	if p.isPunct(":") {
+		if err := p.advance(); err != nil {
+			return textField{}, err
+		}
+		field.value, err = p.parseValue(true)
+	} else if p.isPunct("{") || p.isPunct("<") {
+		// The colon is optional before a nested message
+		field.value, err = p.parseMessage()
+	} else {
+		return textField{}, p.unexpected(`":" or "{"`)
+	}
+	if err != nil {
+		return textField{}, err
+	}
+
+	return field, nil
+}
+
+// parseFieldName parses a plain field name, an unknown field number or a
+// bracketed extension / Any type URL such as [pkg.ext] or [type.googleapis.com/pkg.Msg]
+func (p *textParser) parseFieldName() (string, error) {
+	switch {
+	case p.tok.kind == textIdent:
+		name := p.tok.text
+		return name, p.advance()
+	case p.tok.kind == textNumber && textDecIntPattern.MatchString(p.tok.text):
+		name := p.tok.text
+		return name, p.advance()
+	case p.isPunct("["):
+	default:
+		return "", p.unexpected("field name")
+	}
+
+	if err := p.advance(); err != nil {
+		return "", err
+	}
+
+	var name strings.Builder
+	expectIdent := true
+	for !p.isPunct("]") {
+		switch {
+		case expectIdent && p.tok.kind == textIdent:
+			name.WriteString(p.tok.text)
+			expectIdent = false
+		case !expectIdent && (p.isPunct(".") || p.isPunct("/")):
+			name.WriteString(p.tok.text)
+			expectIdent = true
+		case expectIdent:
+			return "", p.unexpected("extension name")
+		default:
+			return "", p.unexpected(`"]"`)
+		}
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+	}
+	if expectIdent {
+		return "", p.unexpected("extension name")
+	}
+
+	return "[" + name.String() + "]", p.advance()
+}
+
+func (p *textParser) parseMessage() (textValue, error) {
+	closing := "}"
+	if p.isPunct("<") {
+		closing = ">"
+	}
+	if err := p.enter(); err != nil {
+		return textValue{}, err
+	}
+	defer p.leave()
+	if err := p.advance(); err != nil {
+		return textValue{}, err
+	}
+
+	fields, err := p.parseFields(closing)
+	if err != nil {
+		return textValue{}, err
+	}
+	if err := p.expect(closing); err != nil {
+		return textValue{}, err
+	}
+
+	return textValue{kind: textMessageValue, fields: fields}, nil
+}
+
+// parseValue parses a scalar, nested message or (when allowList) a list value
+func (p *textParser) parseValue(allowList bool) (textValue, error) {
+	switch {
+	case p.isPunct("{") || p.isPunct("<"):
+		return p.parseMessage()
+	case allowList && p.isPunct("["):
+		return p.parseList()
+	case p.tok.kind == textString:
+		// Adjacent string literals are concatenated
+		parts := []string{}
+		for p.tok.kind == textString {
+			parts = append(parts, p.tok.text)
+			if err := p.advance(); err != nil {
+				return textValue{}, err
+			}
+		}
+		return textValue{kind: textScalarValue, scalar: strings.Join(parts, " ")}, nil
+	case p.tok.kind == textIdent || p.tok.kind == textNumber:
+		value := p.tok.text
+		return textValue{kind: textScalarValue, scalar: value}, p.advance()
+	case p.isPunct("-"):
+		if err := p.advance(); err != nil {
+			return textValue{}, err
+		}
+		if p.tok.kind == textNumber || (p.tok.kind == textIdent && isSignableIdent(p.tok.text)) {
+			value := "-" + p.tok.text
+			return textValue{kind: textScalarValue, scalar: value}, p.advance()
+		}
+		return textValue{}, p.unexpected("number after \"-\"")
+	default:
+		return textValue{}, p.unexpected("value")
+	}
+}
+
+func isSignableIdent(s string) bool {
+	switch strings.ToLower(s) {
+	case "inf", "infinity", "nan":
+		return true
+	}
+	return false
+}
+
+// parseList parses a repeated field written as [a, b, c]
+func (p *textParser) parseList() (textValue, error) {
+	if err := p.enter(); err != nil {
+		return textValue{}, err
+	}
+	defer p.leave()
+	if err := p.advance(); err != nil {
+		return textValue{}, err
+	}
+
+	list := textValue{kind: textListValue, items: []textValue{}}
+	for !p.isPunct("]") {
+		if len(list.items) > 0 {
+			if err := p.expect(","); err != nil {
+				return textValue{}, err
+			}
+		}
+		item, err := p.parseValue(false)
+		if err != nil {
+			return textValue{}, err
+		}
+		list.items = append(list.items, item)
+	}
+
+	return list, p.advance()
+}
+
+// formatTextFields renders fields either indented (one field per line) or on a
+// single line in the style of ShortDebugString()
+func formatTextFields(fields []textField, indent string) string {
+	var b strings.Builder
+	if indent == "" {
+		writeTextFieldsCompact(&b, fields)
+	} else {
+		writeTextFieldsIndented(&b, fields, indent, 0)
+	}
+	return b.String()
+}
+
+func writeTextFieldsIndented(b *strings.Builder, fields []textField, indent string, depth int) {
+	prefix := strings.Repeat(indent, depth)
+	for _, field := range fields {
+		b.WriteString(prefix)
+		b.WriteString(field.name)
+		if field.value.kind != textMessageValue {
+			b.WriteString(":")
+		}
+		b.WriteString(" ")
+		writeTextValueIndented(b, field.value, indent, depth)
+		b.WriteString("\n")
+	}
+}
+
+func writeTextValueIndented(b *strings.Builder, value textValue, indent string, depth int) {
+	prefix := strings.Repeat(indent, depth)
+	switch value.kind {
+	case textScalarValue:
+		b.WriteString(value.scalar)
+	case textMessageValue:
+		if len(value.fields) == 0 {
+			b.WriteString("{}")
+			return
+		}
+		b.WriteString("{\n")
+		writeTextFieldsIndented(b, value.fields, indent, depth+1)
+		b.WriteString(prefix + "}")
+	case textListValue:
+		if !textListHasMessages(value) {
+			writeTextValueCompact(b, value)
+			return
+		}
+		b.WriteString("[\n")
+		for i, item := range value.items {
+			b.WriteString(prefix + indent)
+			writeTextValueIndented(b, item, indent, depth+1)
+			if i < len(value.items)-1 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(prefix + "]")
+	}
+}
+
+func writeTextFieldsCompact(b *strings.Builder, fields []textField) {
+	for i, field := range fields {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(field.name)
+		if field.value.kind != textMessageValue {
+			b.WriteString(":")
+		}
+		b.WriteString(" ")
+		writeTextValueCompact(b, field.value)
+	}
+}
+
+func writeTextValueCompact(b *strings.Builder, value textValue) {
+	switch value.kind {
+	case textScalarValue:
+		b.WriteString(value.scalar)
+	case textMessageValue:
+		if len(value.fields) == 0 {
+			b.WriteString("{}")
+			return
+		}
+		b.WriteString("{ ")
+		writeTextFieldsCompact(b, value.fields)
+		b.WriteString(" }")
+	case textListValue:
+		b.WriteString("[")
+		for i, item := range value.items {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			writeTextValueCompact(b, item)
+		}
+		b.WriteString("]")
+	}
+}
+
+func textListHasMessages(value textValue) bool {
+	for _, item := range value.items {
+		if item.kind == textMessageValue {
+			return true
+		}
+	}
+	return false
+}
+
+// countTextFields returns the total number of fields, including nested ones
+func countTextFields(fields []textField) int {
+	count := 0
+	for _, field := range fields {
+		count++
+		count += countTextValueFields(field.value)
+	}
+	return count
+}
+
+func countTextValueFields(value textValue) int {
+	count := 0
+	switch value.kind {
+	case textMessageValue:
+		count += countTextFields(value.fields)
+	case textListValue:
+		for _, item := range value.items {
+			count += countTextValueFields(item)
+		}
+	}
+	return count
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+func TestFormatTextFields(t *testing.T) {
+	input := `name: "a\"b" id: -3 # comment
+child < flag: true list: [1, 2] > items: [{ x: 1 }, {}] empty {}`
+
+	fields, err := parseTextFormat(input)
+	if err != nil {
+		t.Fatalf("parseTextFormat: %v", err)
+	}
+
+	wantIndented := `name: "a\"b"
+id: -3
+child {
+  flag: true
+  list: [1, 2]
+}
+items: [
+  {
+    x: 1
+  },
+  {}
+]
+empty {}
+`
+	if got := formatTextFields(fields, "  "); got != wantIndented {
+		t.Errorf("indented =\n%s\nwant\n%s", got, wantIndented)
+	}
+
+	wantCompact := `name: "a\"b" id: -3 child { flag: true list: [1, 2] } items: [{ x: 1 }, {}] empty {}`
+	if got := formatTextFields(fields, ""); got != wantCompact {
+		t.Errorf("compact = %s, want %s", got, wantCompact)
+	}
+
+	if got := countTextFields(fields); got != 8 {
+		t.Errorf("countTextFields = %d, want 8", got)
+	}
+}
+
+func TestParseTextFormatErrors(t *testing.T) {
+	tests := []struct {
+		input  string
+		line   int
+		column int
+	}{
+		{"a: ", 1, 4},
+		{"a {\n  b: 1\n", 3, 1},
+		{"a: 1\nb: \"open", 2, 4},
+		{"a: 1 }", 1, 6},
+		{"a: [1 2]", 1, 7},
+	}
+
+	for _, tt := range tests {
+		_, err := parseTextFormat(tt.input)
+		var syntaxErr *textSyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("parseTextFormat(%q) = %v, want a syntax error", tt.input, err)
+			continue
+		}
+		if syntaxErr.Line != tt.line || syntaxErr.Column != tt.column {
+			t.Errorf("parseTextFormat(%q) error at %d:%d, want %d:%d (%v)",
+				tt.input, syntaxErr.Line, syntaxErr.Column, tt.line, tt.column, err)
+		}
+	}
+}
+
+func TestProtobufToolModes(t *testing.T) {
+	tests := []struct {
+		mode   string
+		output string
+	}{
+		{"format", "a {\n  b: 1\n}\n"},
+		{"minify", "a { b: 1 }"},
+		{"validate", "Valid protobuf text format"},
+	}
+
+	for _, tt := range tests {
+		response, err := protobufTool{}.Process(models.ToolRequest{
+			Input:    "a { b: 1 }",
+			Settings: map[string]interface{}{"mode": tt.mode},
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.mode, err)
+		}
+		if response.Output != tt.output {
+			t.Errorf("%s: output = %q, want %q", tt.mode, response.Output, tt.output)
+		}
+		if response.Metadata["fields"] != 2 {
+			t.Errorf("%s: fields = %v, want 2", tt.mode, response.Metadata["fields"])
+		}
+	}
+}
+
+func TestParseTextFormatNestingLimit(t *testing.T) {
+	nested := func(depth int) string {
+		return strings.Repeat("a {", depth) + strings.Repeat("}", depth)
+	}
+
+	if _, err := parseTextFormat(nested(maxTextDepth)); err != nil {
+		t.Fatalf("depth %d: unexpected error: %v", maxTextDepth, err)
+	}
+
+	_, err := parseTextFormat(nested(maxTextDepth + 1))
+	var syntaxErr *textSyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("depth %d: got %v, want a syntax error", maxTextDepth+1, err)
+	}
+	if !strings.Contains(syntaxErr.Message, "maximum depth") {
+		t.Errorf("message = %q, want it to mention the maximum depth", syntaxErr.Message)
+	}
+}
+
+func TestProtobufToolRejectsDeepNesting(t *testing.T) {
+	input := strings.Repeat("a{", 10000) + strings.Repeat("}", 10000)
+
+	for _, mode := range []string{"format", "minify", "validate"} {
+		response, err := protobufTool{}.Process(models.ToolRequest{
+			Input:    input,
+			Settings: map[string]interface{}{"mode": mode},
+		})
+		if response != nil {
+			t.Errorf("%s: got a response, want an error", mode)
+		}
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: got %v, want ErrInvalidInput", mode, err)
+		}
+	}
+}
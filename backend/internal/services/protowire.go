@@ -0,0 +1,236 @@
+package services
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Schema-less decoder for the protobuf binary wire format, similar to
+// `protoc --decode_raw`. Length-delimited fields are guessed to be nested
+// messages, then strings, and fall back to raw bytes.
+
+// maxWireDepth bounds recursion into nested length-delimited fields
+const maxWireDepth = 64
+
+// wireField is a single decoded field of a binary message
+type wireField struct {
+	number   protowire.Number
+	wireType protowire.Type
+	varint   uint64
+	fixed    uint64
+	bytes    []byte
+	children []wireField
+	isString bool
+}
+
+// decodeWireInput decodes the textual representation of a binary payload.
+// The "auto" encoding treats input made only of hex digits as hex and
+// anything else as base64.
+func decodeWireInput(input, encoding string) ([]byte, error) {
+	compact := strings.Join(strings.Fields(input), "")
+
+	if encoding == "auto" {
+		encoding = "base64"
+		if isHexString(compact) {
+			encoding = "hex"
+		}
+	}
+
+	switch encoding {
+	case "hex":
+		compact = strings.TrimPrefix(strings.TrimPrefix(compact, "0x"), "0X")
+		data, err := hex.DecodeString(compact)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex string: %v", err)
+		}
+		return data, nil
+	case "base64":
+		return decodeBase64Lenient(compact)
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+}
+
+func isHexString(s string) bool {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if s == "" || len(s)%2 != 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isHexDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeWireMessage parses b as a sequence of fields
+func decodeWireMessage(b []byte, depth int) ([]wireField, error) {
+	fields, n, err := decodeWireFields(b, depth, 0)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(b) {
+		return nil, fmt.Errorf("unexpected end group at offset %d", n)
+	}
+	return fields, nil
+}
+
+// decodeWireFields consumes fields until the input ends or an end-group tag
+// for endGroup is found. It returns the number of bytes consumed.
+func decodeWireFields(b []byte, depth int, endGroup protowire.Number) ([]wireField, int, error) {
+	if depth > maxWireDepth {
+		return nil, 0, fmt.Errorf("message nested too deeply")
+	}
+
+	fields := []wireField{}
+	offset := 0
+
+	for offset < len(b) {
+		num, typ, n := protowire.ConsumeTag(b[offset:])
+		if n < 0 {
+			return nil, 0, fmt.Errorf("invalid tag at offset %d: %v", offset, protowire.ParseError(n))
+		}
+		tagOffset := offset
+		offset += n
+
+		field := wireField{number: num, wireType: typ}
+
+		switch typ {
+		case protowire.VarintType:
+			field.varint, n = protowire.ConsumeVarint(b[offset:])
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b[offset:])
+			field.fixed = uint64(v)
+		case protowire.Fixed64Type:
+			field.fixed, n = protowire.ConsumeFixed64(b[offset:])
+		case protowire.BytesType:
+			field.bytes, n = protowire.ConsumeBytes(b[offset:])
+			if n >= 0 {
+				field.children, field.isString = guessBytesContent(field.bytes, depth+1)
+			}
+		case protowire.StartGroupType:
+			var err error
+			field.children, n, err = decodeWireFields(b[offset:], depth+1, num)
+			if err != nil {
+				return nil, 0, err
+			}
+		case protowire.EndGroupType:
+			if num != endGroup {
+				return nil, 0, fmt.Errorf("unexpected end group for field %d at offset %d", num, tagOffset)
+			}
+			return fields, offset, nil
+		default:
+			return nil, 0, fmt.Errorf("invalid wire type %d at offset %d", typ, tagOffset)
+		}
+		if n < 0 {
+			return nil, 0, fmt.Errorf("invalid field %d at offset %d: %v", num, tagOffset, protowire.ParseError(n))
+		}
+		offset += n
+
+		fields = append(fields, field)
+	}
+
+	if endGroup != 0 {
+		return nil, 0, fmt.Errorf("missing end group for field %d", endGroup)
+	}
+
+	return fields, offset, nil
+}
+
+// guessBytesContent decides whether a length-delimited value is a nested
+// message or a printable string
+func guessBytesContent(b []byte, depth int) ([]wireField, bool) {
+	if len(b) > 0 {
+		if children, err := decodeWireMessage(b, depth); err == nil && wireFieldNumbersValid(children) {
+			return children, false
+		}
+	}
+	return nil, isPrintableText(b)
+}
+
+func wireFieldNumbersValid(fields []wireField) bool {
+	for _, field := range fields {
+		if !field.number.IsValid() {
+			return false
+		}
+	}
+	return true
+}
+
+func isPrintableText(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// formatWireFields renders decoded fields with their wire types and all
+// plausible interpretations of each value
+func formatWireFields(fields []wireField, depth int) string {
+	var b strings.Builder
+	writeWireFields(&b, fields, depth)
+	return b.String()
+}
+
+func writeWireFields(b *strings.Builder, fields []wireField, depth int) {
+	prefix := strings.Repeat("  ", depth)
+
+	for _, field := range fields {
+		b.WriteString(prefix)
+
+		switch field.wireType {
+		case protowire.VarintType:
+			v := field.varint
+			fmt.Fprintf(b, "%d [varint]: %d (int64: %d, sint64: %d", field.number, v, int64(v), protowire.DecodeZigZag(v))
+			if v <= 1 {
+				fmt.Fprintf(b, ", bool: %t", v == 1)
+			}
+			b.WriteString(")\n")
+		case protowire.Fixed32Type:
+			v := uint32(field.fixed)
+			fmt.Fprintf(b, "%d [fixed32]: 0x%08x (uint32: %d, int32: %d, float: %g)\n",
+				field.number, v, v, int32(v), math.Float32frombits(v))
+		case protowire.Fixed64Type:
+			v := field.fixed
+			fmt.Fprintf(b, "%d [fixed64]: 0x%016x (uint64: %d, int64: %d, double: %g)\n",
+				field.number, v, v, int64(v), math.Float64frombits(v))
+		case protowire.BytesType:
+			switch {
+			case field.children != nil:
+				fmt.Fprintf(b, "%d [len=%d message] {\n", field.number, len(field.bytes))
+				writeWireFields(b, field.children, depth+1)
+				b.WriteString(prefix + "}\n")
+			case field.isString:
+				fmt.Fprintf(b, "%d [len=%d string]: %q\n", field.number, len(field.bytes), string(field.bytes))
+			default:
+				fmt.Fprintf(b, "%d [len=%d bytes]: %s\n", field.number, len(field.bytes), hex.EncodeToString(field.bytes))
+			}
+		case protowire.StartGroupType:
+			fmt.Fprintf(b, "%d [group] {\n", field.number)
+			writeWireFields(b, field.children, depth+1)
+			b.WriteString(prefix + "}\n")
+		}
+	}
+}
+
+// countWireFields returns the total number of fields, including nested ones
+func countWireFields(fields []wireField) int {
+	count := 0
+	for _, field := range fields {
+		count += 1 + countWireFields(field.children)
+	}
+	return count
+}
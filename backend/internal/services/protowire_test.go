@@ -0,0 +1,114 @@
+package services
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestDecodeWireMessage(t *testing.T) {
+	var nested []byte
+	nested = protowire.AppendTag(nested, 1, protowire.BytesType)
+	// "!" keeps the string from also parsing as a message, as "hi" would
+	nested = protowire.AppendString(nested, "hi!")
+
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.VarintType)
+	b = protowire.AppendVarint(b, 150)
+	b = protowire.AppendTag(b, 2, protowire.Fixed32Type)
+	b = protowire.AppendFixed32(b, math.Float32bits(1.5))
+	b = protowire.AppendTag(b, 3, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, uint64(math.MaxUint64))
+	b = protowire.AppendTag(b, 4, protowire.BytesType)
+	b = protowire.AppendBytes(b, nested)
+	b = protowire.AppendTag(b, 5, protowire.BytesType)
+	b = protowire.AppendBytes(b, []byte{0xff, 0x00})
+	b = protowire.AppendTag(b, 6, protowire.StartGroupType)
+	b = protowire.AppendTag(b, 1, protowire.VarintType)
+	b = protowire.AppendVarint(b, 1)
+	b = protowire.AppendTag(b, 6, protowire.EndGroupType)
+
+	fields, err := decodeWireMessage(b, 0)
+	if err != nil {
+		t.Fatalf("decodeWireMessage: %v", err)
+	}
+
+	want := `1 [varint]: 150 (int64: 150, sint64: 75)
+2 [fixed32]: 0x3fc00000 (uint32: 1069547520, int32: 1069547520, float: 1.5)
+3 [fixed64]: 0xffffffffffffffff (uint64: 18446744073709551615, int64: -1, double: NaN)
+4 [len=5 message] {
+  1 [len=3 string]: "hi!"
+}
+5 [len=2 bytes]: ff00
+6 [group] {
+  1 [varint]: 1 (int64: 1, sint64: -1, bool: true)
+}
+`
+	if got := formatWireFields(fields, 0); got != want {
+		t.Errorf("formatWireFields =\n%s\nwant\n%s", got, want)
+	}
+	if got := countWireFields(fields); got != 8 {
+		t.Errorf("countWireFields = %d, want 8", got)
+	}
+}
+
+func TestDecodeWireMessageMalformed(t *testing.T) {
+	deep := []byte{}
+	for i := 0; i <= maxWireDepth+1; i++ {
+		deep = append(protowire.AppendTag(nil, 1, protowire.StartGroupType), deep...)
+		deep = protowire.AppendTag(deep, 1, protowire.EndGroupType)
+	}
+
+	tests := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{"truncated varint", []byte{0x08, 0x96}, "invalid field 1 at offset 0"},
+		{"truncated fixed32", []byte{0x15, 0x00, 0x00}, "invalid field 2 at offset 0"},
+		{"length past the end", []byte{0x22, 0x05, 0x01}, "invalid field 4 at offset 0"},
+		{"invalid tag", []byte{0x00}, "invalid tag at offset 0"},
+		{"invalid wire type", []byte{0x0f}, "invalid wire type 7 at offset 0"},
+		{"unexpected end group", []byte{0x08, 0x01, 0x0c}, "unexpected end group for field 1 at offset 2"},
+		{"missing end group", []byte{0x0b, 0x08, 0x01}, "missing end group for field 1"},
+		{"nested too deeply", deep, "nested too deeply"},
+	}
+
+	for _, tt := range tests {
+		_, err := decodeWireMessage(tt.input, 0)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want an error containing %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestDecodeWireInput(t *testing.T) {
+	tests := []struct {
+		input    string
+		encoding string
+		want     string
+	}{
+		{"08 96 01", "auto", "\x08\x96\x01"},
+		{"0x089601", "hex", "\x08\x96\x01"},
+		{"CJYB", "auto", "\x08\x96\x01"},
+		{"CJYB", "base64", "\x08\x96\x01"},
+		{"_w", "auto", "\xff"},
+	}
+
+	for _, tt := range tests {
+		got, err := decodeWireInput(tt.input, tt.encoding)
+		if err != nil {
+			t.Errorf("decodeWireInput(%q, %s): %v", tt.input, tt.encoding, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("decodeWireInput(%q, %s) = %x, want %x", tt.input, tt.encoding, got, tt.want)
+		}
+	}
+
+	if _, err := decodeWireInput("0g", "hex"); err == nil {
+		t.Error("decodeWireInput accepted invalid hex")
+	}
+}
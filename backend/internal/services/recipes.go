@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"web-tools-platform/backend/internal/database"
+	"web-tools-platform/backend/internal/models"
+)
+
+const (
+	// recipeIDLength is the length of generated recipe IDs
+	recipeIDLength = 8
+	// recipeIDAlphabet avoids characters that are easily confused in URLs
+	recipeIDAlphabet = "23456789abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
+	// maxRecipeIDAttempts bounds retries after an ID collision
+	maxRecipeIDAttempts = 5
+)
+
+// ErrInvalidRecipe is returned when a recipe fails validation
+var ErrInvalidRecipe = errors.New("invalid recipe")
+
+// GetRecipes returns all saved recipes
+func (s *Service) GetRecipes(ctx context.Context) ([]models.Recipe, error) {
+	return s.db.GetRecipes(ctx)
+}
+
+// GetRecipe returns a saved recipe by ID
+func (s *Service) GetRecipe(ctx context.Context, id string) (*models.Recipe, error) {
+	return s.db.GetRecipe(ctx, id)
+}
+
+// CreateRecipe validates and stores a new recipe under a short random ID
+func (s *Service) CreateRecipe(ctx context.Context, request models.RecipeRequest) (*models.Recipe, error) {
+	if err := s.validateRecipe(request); err != nil {
+		return nil, err
+	}
+
+	recipe := &models.Recipe{
+		Name:        strings.TrimSpace(request.Name),
+		Description: request.Description,
+		Steps:       request.Steps,
+	}
+
+	for attempt := 0; attempt < maxRecipeIDAttempts; attempt++ {
+		id, err := newRecipeID()
+		if err != nil {
+			return nil, err
+		}
+		recipe.ID = id
+
+		err = s.db.CreateRecipe(ctx, recipe)
+		if errors.Is(err, database.ErrConflict) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return recipe, nil
+	}
+
+	return nil, fmt.Errorf("failed to allocate a unique recipe ID")
+}
+
+// UpdateRecipe validates and replaces an existing recipe
+func (s *Service) UpdateRecipe(ctx context.Context, id string, request models.RecipeRequest) (*models.Recipe, error) {
+	if err := s.validateRecipe(request); err != nil {
+		return nil, err
+	}
+
+	recipe := &models.Recipe{
+		ID:          id,
+		Name:        strings.TrimSpace(request.Name),
+		Description: request.Description,
+		Steps:       request.Steps,
+	}
+	if err := s.db.UpdateRecipe(ctx, recipe); err != nil {
+		return nil, err
+	}
+
+	return recipe, nil
+}
+
+// DeleteRecipe removes a saved recipe
+func (s *Service) DeleteRecipe(ctx context.Context, id string) error {
+	return s.db.DeleteRecipe(ctx, id)
+}
+
+// RunRecipe runs the input through the steps of a saved recipe
+func (s *Service) RunRecipe(ctx context.Context, id string, input string) (*models.ToolResponse, error) {
+	recipe, err := s.db.GetRecipe(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.ProcessPipeline(ctx, models.PipelineRequest{
+		Input: input,
+		Steps: recipe.Steps,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response.Metadata["recipeId"] = recipe.ID
+	return response, nil
+}
+
+// validateRecipe checks the name and steps of a recipe
+func (s *Service) validateRecipe(request models.RecipeRequest) error {
+	if strings.TrimSpace(request.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidRecipe)
+	}
+	if err := s.validatePipeline(request.Steps); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRecipe, err)
+	}
+	return nil
+}
+
+// newRecipeID returns a short random ID suitable for sharing in URLs
+func newRecipeID() (string, error) {
+	max := big.NewInt(int64(len(recipeIDAlphabet)))
+
+	var b strings.Builder
+	for i := 0; i < recipeIDLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(recipeIDAlphabet[n.Int64()])
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"web-tools-platform/backend/internal/database"
+	"web-tools-platform/backend/internal/models"
+)
+
+// conflictStore reports the first conflicts recipe IDs as taken
+type conflictStore struct {
+	database.Store
+	conflicts int
+	ids       []string
+}
+
+func (s *conflictStore) CreateRecipe(ctx context.Context, recipe *models.Recipe) error {
+	s.ids = append(s.ids, recipe.ID)
+	if len(s.ids) <= s.conflicts {
+		return database.ErrConflict
+	}
+	return s.Store.CreateRecipe(ctx, recipe)
+}
+
+func TestCreateAndRunRecipe(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	recipe, err := s.CreateRecipe(ctx, models.RecipeRequest{
+		Name: "  Encode twice ",
+		Steps: []models.PipelineStep{
+			{ToolID: "base64", Settings: map[string]interface{}{"mode": "encode"}},
+			{ToolID: "base64", Settings: map[string]interface{}{"mode": "encode"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateRecipe: %v", err)
+	}
+	if recipe.Name != "Encode twice" {
+		t.Errorf("name = %q, want it trimmed", recipe.Name)
+	}
+	if len(recipe.ID) != recipeIDLength || strings.Trim(recipe.ID, recipeIDAlphabet) != "" {
+		t.Errorf("ID = %q, want %d characters of the recipe ID alphabet", recipe.ID, recipeIDLength)
+	}
+
+	response, err := s.RunRecipe(ctx, recipe.ID, "hi")
+	if err != nil {
+		t.Fatalf("RunRecipe: %v", err)
+	}
+	if response.Output != "YUdrPQ==" || response.Metadata["recipeId"] != recipe.ID {
+		t.Errorf("RunRecipe = %+v, want hi encoded twice", response)
+	}
+
+	if _, err := s.RunRecipe(ctx, "missing", "hi"); !errors.Is(err, database.ErrNotFound) {
+		t.Errorf("RunRecipe of an unknown recipe = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCreateRecipeValidation(t *testing.T) {
+	s := newTestService(t)
+
+	tests := map[string]models.RecipeRequest{
+		"no name":      {Name: " ", Steps: []models.PipelineStep{{ToolID: "json"}}},
+		"no steps":     {Name: "Empty"},
+		"unknown tool": {Name: "Unknown", Steps: []models.PipelineStep{{ToolID: "missing"}}},
+	}
+	for name, request := range tests {
+		if _, err := s.CreateRecipe(context.Background(), request); !errors.Is(err, ErrInvalidRecipe) {
+			t.Errorf("%s: got %v, want ErrInvalidRecipe", name, err)
+		}
+	}
+}
+
+func TestCreateRecipeRetriesTakenIDs(t *testing.T) {
+	base := newTestService(t)
+	request := models.RecipeRequest{Name: "Format", Steps: []models.PipelineStep{{ToolID: "json"}}}
+
+	store := &conflictStore{Store: base.db, conflicts: maxRecipeIDAttempts - 1}
+	s := NewService(store)
+	recipe, err := s.CreateRecipe(context.Background(), request)
+	if err != nil {
+		t.Fatalf("CreateRecipe: %v", err)
+	}
+	if len(store.ids) != maxRecipeIDAttempts || recipe.ID != store.ids[len(store.ids)-1] {
+		t.Errorf("tried IDs %v and kept %s, want a new ID per attempt and the last kept", store.ids, recipe.ID)
+	}
+	if store.ids[0] == store.ids[1] {
+		t.Errorf("retried with the same ID %s", store.ids[0])
+	}
+
+	store = &conflictStore{Store: base.db, conflicts: maxRecipeIDAttempts}
+	if _, err := NewService(store).CreateRecipe(context.Background(), request); err == nil {
+		t.Error("CreateRecipe succeeded although every ID was taken")
+	}
+	if len(store.ids) != maxRecipeIDAttempts {
+		t.Errorf("%d attempts, want %d", len(store.ids), maxRecipeIDAttempts)
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+// Tool is implemented by every processor exposed through the API
+type Tool interface {
+	// ID returns the unique identifier used in /api/v1/tools/:toolId
+	ID() string
+	// Metadata returns the catalog entry describing the tool
+	Metadata() models.Tool
+	// SettingsSchema describes the settings accepted by Process
+	SettingsSchema() models.SettingsSchema
+	// Process runs the tool against the given request. Input or settings
+	// it cannot process are reported as a *ToolError, other errors are
+	// treated as server failures.
+	Process(request models.ToolRequest) (*models.ToolResponse, error)
+}
+
+// Registry holds the set of available tools
+type Registry struct {
+	mu    sync.RWMutex
+	tools map[string]Tool
+}
+
+// NewRegistry creates an empty tool registry
+func NewRegistry() *Registry {
+	return &Registry{
+		tools: make(map[string]Tool),
+	}
+}
+
+// defaultRegistry is populated by the tool implementations in this package
+var defaultRegistry = NewRegistry()
+
+// Register adds a tool to the default registry. It panics if a tool with the
+// same ID is already registered.
+func Register(tool Tool) {
+	if err := defaultRegistry.Register(tool); err != nil {
+		panic(err)
+	}
+}
+
+// Register adds a tool to the registry
+func (r *Registry) Register(tool Tool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := tool.ID()
+	if id == "" {
+		return fmt.Errorf("tool ID is required")
+	}
+	if _, exists := r.tools[id]; exists {
+		return fmt.Errorf("tool already registered: %s", id)
+	}
+
+	r.tools[id] = tool
+	return nil
+}
+
+// Get returns the tool registered under id
+func (r *Registry) Get(id string) (Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tool, ok := r.tools[id]
+	return tool, ok
+}
+
+// List returns all registered tools ordered by ID
+func (r *Registry) List() []Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tools := make([]Tool, 0, len(r.tools))
+	for _, tool := range r.tools {
+		tools = append(tools, tool)
+	}
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].ID() < tools[j].ID()
+	})
+
+	return tools
+}
+
+// stringSetting returns the string setting stored under key, or def if unset
+func stringSetting(settings map[string]interface{}, key, def string) string {
+	if settings != nil {
+		if v, ok := settings[key].(string); ok {
+			return v
+		}
+	}
+	return def
+}
+
+// modeSchema builds a settings schema with a single "mode" enum
+func modeSchema(def string, modes ...string) models.SettingsSchema {
+	enum := make([]interface{}, len(modes))
+	for i, m := range modes {
+		enum[i] = m
+	}
+
+	return models.SettingsSchema{
+		Type: "object",
+		Properties: map[string]models.SettingProperty{
+			"mode": {
+				Type:        "string",
+				Description: "Processing mode",
+				Enum:        enum,
+				Default:     def,
+			},
+		},
+	}
+}
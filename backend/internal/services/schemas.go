@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+// Schema kinds stored in the proto_schemas table
+const (
+	SchemaKindProto         = "proto"
+	SchemaKindDescriptorSet = "descriptor_set"
+)
+
+// ErrInvalidSchema is returned when an uploaded schema cannot be compiled
+var ErrInvalidSchema = errors.New("invalid schema")
+
+// CreateSchema compiles and stores an uploaded schema. files maps upload file
+// names to their contents and must either hold .proto sources or a single
+// serialized FileDescriptorSet.
+func (s *Service) CreateSchema(ctx context.Context, name string, files map[string][]byte) (*models.ProtoSchema, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: no files uploaded", ErrInvalidSchema)
+	}
+
+	kind := SchemaKindProto
+	for filename := range files {
+		if path.Ext(filename) != ".proto" {
+			kind = SchemaKindDescriptorSet
+		}
+	}
+
+	var set *descriptorpb.FileDescriptorSet
+	var err error
+	switch {
+	case kind == SchemaKindProto:
+		set, err = compileProtoFiles(files)
+	case len(files) == 1:
+		for _, content := range files {
+			set, err = parseDescriptorSet(content)
+		}
+	default:
+		err = errors.New("descriptor sets must be uploaded on their own")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+
+	descriptor, err := proto.Marshal(set)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &models.ProtoSchema{
+		ID:         uuid.New().String(),
+		Name:       name,
+		Kind:       kind,
+		Descriptor: descriptor,
+	}
+	if err := describeSchema(schema); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+
+	if err := s.db.CreateSchema(ctx, schema); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// GetSchemas returns all stored schemas
+func (s *Service) GetSchemas(ctx context.Context) ([]models.ProtoSchema, error) {
+	schemas, err := s.db.GetSchemas(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range schemas {
+		if err := describeSchema(&schemas[i]); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", schemas[i].ID, err)
+		}
+	}
+
+	return schemas, nil
+}
+
+// GetSchema returns a stored schema by ID
+func (s *Service) GetSchema(ctx context.Context, id string) (*models.ProtoSchema, error) {
+	schema, err := s.db.GetSchema(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := describeSchema(schema); err != nil {
+		return nil, fmt.Errorf("schema %s: %w", id, err)
+	}
+
+	return schema, nil
+}
+
+// DeleteSchema removes a stored schema
+func (s *Service) DeleteSchema(ctx context.Context, id string) error {
+	return s.db.DeleteSchema(ctx, id)
+}
+
+// DecodeWithSchema converts a binary, JSON or text format payload of the
+// requested message type into the requested representation. Malformed
+// payloads, unknown message types and formats are reported as ToolErrors.
+func (s *Service) DecodeWithSchema(ctx context.Context, id string, request models.SchemaDecodeRequest) (*models.ToolResponse, error) {
+	schema, err := s.db.GetSchema(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := schemaFiles(schema.Descriptor)
+	if err != nil {
+		return nil, fmt.Errorf("schema %s: %w", id, err)
+	}
+
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(strings.TrimPrefix(request.MessageType, ".")))
+	if err != nil {
+		return nil, &ToolError{
+			Kind:    ErrInvalidInput,
+			Message: fmt.Sprintf("Unknown message type: %s", request.MessageType),
+			Details: map[string]interface{}{"message_type": request.MessageType},
+		}
+	}
+	messageDesc, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, &ToolError{
+			Kind:    ErrInvalidInput,
+			Message: fmt.Sprintf("Not a message type: %s", request.MessageType),
+			Details: map[string]interface{}{"message_type": request.MessageType},
+		}
+	}
+
+	types := dynamicpb.NewTypes(files)
+	message := dynamicpb.NewMessage(messageDesc)
+
+	inputFormat := defaultString(request.InputFormat, "binary")
+	outputFormat := defaultString(request.OutputFormat, "json")
+	encoding := defaultString(request.Encoding, "auto")
+
+	switch inputFormat {
+	case "binary":
+		data, err := decodeWireInput(request.Input, encoding)
+		if err != nil {
+			return nil, invalidInput("Invalid binary input: %v", err)
+		}
+		err = proto.UnmarshalOptions{Resolver: types}.Unmarshal(data, message)
+		if err != nil {
+			return nil, invalidInput("Invalid protobuf binary: %v", err)
+		}
+	case "json":
+		err := protojson.UnmarshalOptions{Resolver: types}.Unmarshal([]byte(request.Input), message)
+		if err != nil {
+			return nil, invalidInput("Invalid protobuf JSON: %v", err)
+		}
+	case "text":
+		err := prototext.UnmarshalOptions{Resolver: types}.Unmarshal([]byte(request.Input), message)
+		if err != nil {
+			return nil, invalidInput("Invalid protobuf text format: %v", err)
+		}
+	default:
+		return nil, unsupportedOption("input_format", "input format", inputFormat)
+	}
+
+	var output string
+
+	switch outputFormat {
+	case "json":
+		data, err := protojson.MarshalOptions{Resolver: types, Multiline: true, Indent: "  "}.Marshal(message)
+		if err != nil {
+			return nil, fmt.Errorf("encoding JSON: %w", err)
+		}
+		output = string(data)
+	case "text":
+		data, err := prototext.MarshalOptions{Resolver: types, Multiline: true, Indent: "  "}.Marshal(message)
+		if err != nil {
+			return nil, fmt.Errorf("encoding text format: %w", err)
+		}
+		output = string(data)
+	case "binary":
+		data, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+		if err != nil {
+			return nil, fmt.Errorf("encoding binary: %w", err)
+		}
+		if encoding == "hex" {
+			output = hex.EncodeToString(data)
+		} else {
+			output = base64.StdEncoding.EncodeToString(data)
+		}
+	default:
+		return nil, unsupportedOption("output_format", "output format", outputFormat)
+	}
+
+	return &models.ToolResponse{
+		Output: output,
+		Metadata: map[string]interface{}{
+			"message_type": string(messageDesc.FullName()),
+		},
+	}, nil
+}
+
+// compileProtoFiles compiles .proto sources into a self-contained descriptor
+// set. Imports of the well-known google/protobuf types are always available.
+func compileProtoFiles(files map[string][]byte) (*descriptorpb.FileDescriptorSet, error) {
+	sources := make(map[string]string, len(files))
+	names := make([]string, 0, len(files))
+	for filename, content := range files {
+		sources[filename] = string(content)
+		names = append(names, filename)
+	}
+	sort.Strings(names)
+
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(sources),
+		}),
+	}
+
+	compiled, err := compiler.Compile(context.Background(), names...)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := map[string]bool{}
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		// Dependencies come first so the set can be loaded in order
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(fd))
+	}
+	for _, fd := range compiled {
+		add(fd)
+	}
+
+	return set, nil
+}
+
+// parseDescriptorSet parses a serialized FileDescriptorSet, as produced by
+// `protoc --descriptor_set_out --include_imports`
+func parseDescriptorSet(content []byte) (*descriptorpb.FileDescriptorSet, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(content, set); err != nil {
+		return nil, fmt.Errorf("not a FileDescriptorSet: %v", err)
+	}
+	if len(set.File) == 0 {
+		return nil, errors.New("descriptor set contains no files")
+	}
+
+	if _, err := protodesc.NewFiles(set); err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+// schemaFiles builds a descriptor registry from a stored descriptor set
+func schemaFiles(descriptor []byte) (*protoregistry.Files, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(descriptor, set); err != nil {
+		return nil, err
+	}
+
+	return protodesc.NewFiles(set)
+}
+
+// describeSchema fills in the file and message names of a schema
+func describeSchema(schema *models.ProtoSchema) error {
+	files, err := schemaFiles(schema.Descriptor)
+	if err != nil {
+		return err
+	}
+
+	schema.Files = []string{}
+	schema.Messages = []string{}
+
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		schema.Files = append(schema.Files, fd.Path())
+		collectMessageNames(fd.Messages(), &schema.Messages)
+		return true
+	})
+	sort.Strings(schema.Files)
+	sort.Strings(schema.Messages)
+
+	return nil
+}
+
+func collectMessageNames(messages protoreflect.MessageDescriptors, names *[]string) {
+	for i := 0; i < messages.Len(); i++ {
+		message := messages.Get(i)
+		if message.IsMapEntry() {
+			continue
+		}
+		*names = append(*names, string(message.FullName()))
+		collectMessageNames(message.Messages(), names)
+	}
+}
+
+func defaultString(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
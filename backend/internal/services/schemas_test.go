@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"web-tools-platform/backend/internal/database"
+	"web-tools-platform/backend/internal/models"
+)
+
+const personProto = `syntax = "proto3";
+package test;
+
+import "google/protobuf/timestamp.proto";
+
+message Person {
+  string name = 1;
+  int32 id = 2;
+  repeated string emails = 3;
+  google.protobuf.Timestamp born = 4;
+}
+`
+
+func TestCreateSchema(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	schema, err := s.CreateSchema(ctx, "people", map[string][]byte{"person.proto": []byte(personProto)})
+	if err != nil {
+		t.Fatalf("CreateSchema: %v", err)
+	}
+	// The descriptor set is self-contained, so it includes the imports
+	if schema.Kind != SchemaKindProto {
+		t.Errorf("kind = %s, want %s", schema.Kind, SchemaKindProto)
+	}
+	if want := []string{"google/protobuf/timestamp.proto", "person.proto"}; !reflect.DeepEqual(schema.Files, want) {
+		t.Errorf("files = %v, want %v", schema.Files, want)
+	}
+	if want := []string{"google.protobuf.Timestamp", "test.Person"}; !reflect.DeepEqual(schema.Messages, want) {
+		t.Errorf("messages = %v, want %v", schema.Messages, want)
+	}
+
+	// The stored descriptor set can be uploaded again on its own
+	stored, err := s.GetSchema(ctx, schema.ID)
+	if err != nil {
+		t.Fatalf("GetSchema: %v", err)
+	}
+	set, err := s.CreateSchema(ctx, "people again", map[string][]byte{"people.pb": stored.Descriptor})
+	if err != nil {
+		t.Fatalf("CreateSchema with a descriptor set: %v", err)
+	}
+	if set.Kind != SchemaKindDescriptorSet || !proto.Equal(mustDescriptorSet(t, set.Descriptor), mustDescriptorSet(t, stored.Descriptor)) {
+		t.Errorf("descriptor set schema = %+v, want the same descriptors", set)
+	}
+
+	if schemas, err := s.GetSchemas(ctx); err != nil || len(schemas) != 2 {
+		t.Errorf("GetSchemas = %d schemas, %v; want 2", len(schemas), err)
+	}
+
+	if err := s.DeleteSchema(ctx, schema.ID); err != nil {
+		t.Fatalf("DeleteSchema: %v", err)
+	}
+	if _, err := s.GetSchema(ctx, schema.ID); !errors.Is(err, database.ErrNotFound) {
+		t.Errorf("GetSchema after delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCreateSchemaInvalid(t *testing.T) {
+	s := newTestService(t)
+
+	tests := map[string]map[string][]byte{
+		"no files":       nil,
+		"syntax error":   {"broken.proto": []byte("message {")},
+		"missing import": {"a.proto": []byte(`syntax = "proto3"; import "b.proto";`)},
+		"not a set":      {"set.pb": []byte("not a descriptor set")},
+		"mixed":          {"a.proto": []byte(personProto), "set.pb": nil},
+	}
+	for name, files := range tests {
+		if _, err := s.CreateSchema(context.Background(), name, files); !errors.Is(err, ErrInvalidSchema) {
+			t.Errorf("%s: got %v, want ErrInvalidSchema", name, err)
+		}
+	}
+}
+
+func TestDecodeWithSchema(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	schema, err := s.CreateSchema(ctx, "people", map[string][]byte{"person.proto": []byte(personProto)})
+	if err != nil {
+		t.Fatalf("CreateSchema: %v", err)
+	}
+
+	decode := func(request models.SchemaDecodeRequest) string {
+		t.Helper()
+		request.MessageType = "test.Person"
+		response, err := s.DecodeWithSchema(ctx, schema.ID, request)
+		if err != nil {
+			t.Fatalf("DecodeWithSchema(%+v): %v", request, err)
+		}
+		return response.Output
+	}
+
+	// name: "Ada", id: 7, emails: "ada@example.com"
+	const binary = "0a034164611007" + "1a0f" + "616461406578616d706c652e636f6d"
+
+	// protojson and prototext randomise their spacing, so compare the
+	// outputs with runs of whitespace collapsed
+	collapse := func(s string) string { return strings.Join(strings.Fields(s), " ") }
+
+	json := decode(models.SchemaDecodeRequest{Input: binary})
+	for _, want := range []string{`"name": "Ada"`, `"id": 7`, `"ada@example.com"`} {
+		if !strings.Contains(collapse(json), want) {
+			t.Errorf("JSON output %s, want it to contain %s", json, want)
+		}
+	}
+
+	text := decode(models.SchemaDecodeRequest{Input: json, InputFormat: "json", OutputFormat: "text"})
+	if !strings.Contains(collapse(text), `name: "Ada" id: 7`) {
+		t.Errorf("text output = %s, want the decoded fields", text)
+	}
+
+	if got := decode(models.SchemaDecodeRequest{Input: text, InputFormat: "text", OutputFormat: "binary", Encoding: "hex"}); got != binary {
+		t.Errorf("binary output = %s, want %s", got, binary)
+	}
+}
+
+func TestDecodeWithSchemaErrors(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	schema, err := s.CreateSchema(ctx, "people", map[string][]byte{"person.proto": []byte(personProto)})
+	if err != nil {
+		t.Fatalf("CreateSchema: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		request models.SchemaDecodeRequest
+		kind    error
+	}{
+		{"unknown message", models.SchemaDecodeRequest{MessageType: "test.Nobody"}, ErrInvalidInput},
+		{"not a message", models.SchemaDecodeRequest{MessageType: "test.Person.name"}, ErrInvalidInput},
+		{"malformed binary", models.SchemaDecodeRequest{MessageType: "test.Person", Input: "0a05"}, ErrInvalidInput},
+		{"malformed JSON", models.SchemaDecodeRequest{MessageType: "test.Person", Input: "{", InputFormat: "json"}, ErrInvalidInput},
+		{"unknown input format", models.SchemaDecodeRequest{MessageType: "test.Person", InputFormat: "xml"}, ErrUnsupportedMode},
+		{"unknown output format", models.SchemaDecodeRequest{MessageType: "test.Person", OutputFormat: "xml"}, ErrUnsupportedMode},
+	}
+	for _, tt := range tests {
+		if _, err := s.DecodeWithSchema(ctx, schema.ID, tt.request); !errors.Is(err, tt.kind) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.kind)
+		}
+	}
+
+	if _, err := s.DecodeWithSchema(ctx, "missing", models.SchemaDecodeRequest{MessageType: "test.Person"}); !errors.Is(err, database.ErrNotFound) {
+		t.Errorf("unknown schema: got %v, want ErrNotFound", err)
+	}
+}
+
+func mustDescriptorSet(t *testing.T, b []byte) *descriptorpb.FileDescriptorSet {
+	t.Helper()
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(b, set); err != nil {
+		t.Fatalf("unmarshalling the descriptor set: %v", err)
+	}
+	return set
+}
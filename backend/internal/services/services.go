@@ -1,319 +1,217 @@
 package services
 
 import (
-	"encoding/base64"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"html"
-	"net/url"
-	"strconv"
-	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"web-tools-platform/backend/internal/database"
+	"web-tools-platform/backend/internal/metrics"
 	"web-tools-platform/backend/internal/models"
 )
 
+// tracer creates the spans of the service layer
+var tracer = otel.Tracer("web-tools-platform/backend/internal/services")
+
 // Service handles business logic
 type Service struct {
-	db *database.DB
+	db       database.Store
+	registry *Registry
+	limits   Limits
+}
+
+// Limits bound the work of a single tool run
+type Limits struct {
+	// MaxInputBytes is the largest input accepted
+	MaxInputBytes int
+	// Timeout is how long a tool may run. Tools cannot be interrupted, so a
+	// run that times out finishes in the background and its result is
+	// discarded.
+	Timeout time.Duration
+	// PipelineTimeout is how long all the steps of a pipeline may run
+	// together
+	PipelineTimeout time.Duration
+}
+
+// DefaultLimits are used unless WithLimits sets others
+var DefaultLimits = Limits{
+	MaxInputBytes:   1 << 20,
+	Timeout:         10 * time.Second,
+	PipelineTimeout: 30 * time.Second,
 }
 
 // NewService creates a new service instance
-func NewService(db *database.DB) *Service {
+func NewService(db database.Store) *Service {
 	return &Service{
-		db: db,
+		db:       db,
+		registry: defaultRegistry,
+		limits:   DefaultLimits,
 	}
 }
 
-// GetTools returns all available tools
-func (s *Service) GetTools() ([]models.Tool, error) {
-	tools := []models.Tool{
-		{
-			ID:          "base64",
-			Name:        "Base64 Encoder/Decoder",
-			Description: "Encode and decode Base64 strings",
-			Category:    "encoding",
-			Icon:        "base64",
-			Features:    []string{"encode", "decode", "url-safe", "multiline"},
-		},
-		{
-			ID:          "json",
-			Name:        "JSON Formatter/Validator",
-			Description: "Format and validate JSON data",
-			Category:    "formatting",
-			Icon:        "json",
-			Features:    []string{"format", "validate", "minify", "prettify"},
-		},
-		{
-			ID:          "url",
-			Name:        "URL Encoder/Decoder",
-			Description: "Encode and decode URL parameters",
-			Category:    "encoding",
-			Icon:        "url",
-			Features:    []string{"encode", "decode", "component", "full-url"},
-		},
-		{
-			ID:          "html",
-			Name:        "HTML Encoder/Decoder",
-			Description: "Encode and decode HTML entities",
-			Category:    "encoding",
-			Icon:        "html",
-			Features:    []string{"encode", "decode", "entities", "escape"},
-		},
-		{
-			ID:          "unicode",
-			Name:        "Unicode Encoder/Decoder",
-			Description: "Encode and decode Unicode characters",
-			Category:    "encoding",
-			Icon:        "unicode",
-			Features:    []string{"encode", "decode", "normalize", "categories"},
-		},
-	}
-
-	return tools, nil
+// WithLimits returns a copy of the service that runs tools within limits
+func (s *Service) WithLimits(limits Limits) *Service {
+	limited := *s
+	limited.limits = limits
+	return &limited
 }
 
-// GetTool returns a specific tool by ID
-func (s *Service) GetTool(id string) (*models.Tool, error) {
-	tools, err := s.GetTools()
-	if err != nil {
-		return nil, err
-	}
+// Catalog returns the metadata of every registered tool, used to seed the
+// tools table
+func Catalog() []models.Tool {
+	registered := defaultRegistry.List()
 
-	for _, tool := range tools {
-		if tool.ID == id {
-			return &tool, nil
-		}
+	tools := make([]models.Tool, 0, len(registered))
+	for _, tool := range registered {
+		tools = append(tools, tool.Metadata())
 	}
 
-	return nil, fmt.Errorf("tool not found: %s", id)
+	return tools
 }
 
-// ProcessTool processes input using the specified tool
-func (s *Service) ProcessTool(toolID string, request models.ToolRequest) (*models.ToolResponse, error) {
-	switch toolID {
-	case "base64":
-		return s.processBase64(request)
-	case "json":
-		return s.processJSON(request)
-	case "url":
-		return s.processURL(request)
-	case "html":
-		return s.processHTML(request)
-	case "unicode":
-		return s.processUnicode(request)
-	default:
-		return nil, fmt.Errorf("unsupported tool: %s", toolID)
+// SettingsSchemas returns the settings schema of every registered tool, by
+// tool ID
+func SettingsSchemas() map[string]models.SettingsSchema {
+	schemas := map[string]models.SettingsSchema{}
+	for _, tool := range defaultRegistry.List() {
+		schemas[tool.ID()] = tool.SettingsSchema()
 	}
+	return schemas
 }
 
-// processBase64 handles base64 encoding/decoding
-func (s *Service) processBase64(request models.ToolRequest) (*models.ToolResponse, error) {
-	mode := "encode"
-	if request.Settings != nil {
-		if m, ok := request.Settings["mode"].(string); ok {
-			mode = m
-		}
-	}
-
-	var output string
-
-	switch mode {
-	case "encode":
-		output = base64.StdEncoding.EncodeToString([]byte(request.Input))
-	case "decode":
-		decoded, decodeErr := base64.StdEncoding.DecodeString(request.Input)
-		if decodeErr != nil {
-			return &models.ToolResponse{
-				Error: fmt.Sprintf("Invalid base64 string: %v", decodeErr),
-			}, nil
-		}
-		output = string(decoded)
-	case "url-encode":
-		output = base64.URLEncoding.EncodeToString([]byte(request.Input))
-	case "url-decode":
-		decoded, decodeErr := base64.URLEncoding.DecodeString(request.Input)
-		if decodeErr != nil {
-			return &models.ToolResponse{
-				Error: fmt.Sprintf("Invalid base64 URL string: %v", decodeErr),
-			}, nil
-		}
-		output = string(decoded)
-	default:
-		return &models.ToolResponse{
-			Error: fmt.Sprintf("Unsupported mode: %s", mode),
-		}, nil
-	}
-
-	return &models.ToolResponse{
-		Output: output,
-	}, nil
+// GetTools returns all available tools
+func (s *Service) GetTools(ctx context.Context) ([]models.Tool, error) {
+	return s.db.GetTools(ctx)
 }
 
-// processJSON handles JSON formatting and validation
-func (s *Service) processJSON(request models.ToolRequest) (*models.ToolResponse, error) {
-	mode := "format"
-	if request.Settings != nil {
-		if m, ok := request.Settings["mode"].(string); ok {
-			mode = m
-		}
+// GetTool returns a specific tool by ID with its settings schema
+func (s *Service) GetTool(ctx context.Context, id string) (*models.Tool, error) {
+	tool, err := s.db.GetTool(ctx, id)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, toolNotFound(id)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	var output string
-	var jsonData interface{}
-
-	// First, validate the JSON
-	if err := json.Unmarshal([]byte(request.Input), &jsonData); err != nil {
-		return &models.ToolResponse{
-			Error: fmt.Sprintf("Invalid JSON: %v", err),
-		}, nil
+	if registered, ok := s.registry.Get(id); ok {
+		schema := registered.SettingsSchema()
+		tool.SettingsSchema = &schema
 	}
+	return tool, nil
+}
 
-	switch mode {
-	case "format", "prettify":
-		formatted, err := json.MarshalIndent(jsonData, "", "  ")
-		if err != nil {
-			return &models.ToolResponse{
-				Error: fmt.Sprintf("Error formatting JSON: %v", err),
-			}, nil
-		}
-		output = string(formatted)
-	case "minify":
-		minified, err := json.Marshal(jsonData)
-		if err != nil {
-			return &models.ToolResponse{
-				Error: fmt.Sprintf("Error minifying JSON: %v", err),
-			}, nil
-		}
-		output = string(minified)
-	case "validate":
-		output = "Valid JSON"
-	default:
-		return &models.ToolResponse{
-			Error: fmt.Sprintf("Unsupported mode: %s", mode),
-		}, nil
+// ProcessTool processes input using the specified tool and records successful
+// invocations in the tool history of userID. Failures caused by the request
+// or the limits are returned as a *ToolError.
+func (s *Service) ProcessTool(ctx context.Context, userID, toolID string, request models.ToolRequest) (*models.ToolResponse, error) {
+	response, err := s.processTool(ctx, toolID, request)
+	if err != nil {
+		return nil, err
 	}
 
-	return &models.ToolResponse{
-		Output: output,
-	}, nil
+	s.recordHistory(ctx, userID, toolID, request, response)
+	return response, nil
 }
 
-// processURL handles URL encoding/decoding
-func (s *Service) processURL(request models.ToolRequest) (*models.ToolResponse, error) {
-	mode := "encode"
-	if request.Settings != nil {
-		if m, ok := request.Settings["mode"].(string); ok {
-			mode = m
-		}
+// processTool runs a registered tool without recording history
+func (s *Service) processTool(ctx context.Context, toolID string, request models.ToolRequest) (*models.ToolResponse, error) {
+	tool, ok := s.registry.Get(toolID)
+	if !ok {
+		return nil, toolNotFound(toolID)
+	}
+
+	ctx, span := tracer.Start(ctx, "Service.ProcessTool", trace.WithAttributes(
+		attribute.String("tool.id", toolID),
+		attribute.Int("tool.input_bytes", len(request.Input)),
+	))
+	defer span.End()
+
+	started := time.Now()
+	response, err := s.runTool(ctx, tool, request)
+
+	result, outputBytes := "failed", 0
+	var toolErr *ToolError
+	errors.As(err, &toolErr)
+	switch {
+	case err == nil:
+		result, outputBytes = "ok", len(response.Output)
+	case errors.Is(err, ErrToolTimeout):
+		result = "timeout"
+	case toolErr != nil:
+		result = "tool_error"
+	}
+	metrics.ObserveTool(toolID, result, time.Since(started), len(request.Input), outputBytes)
+
+	span.SetAttributes(
+		attribute.String("tool.result", result),
+		attribute.Int("tool.output_bytes", outputBytes),
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 	}
 
-	var output string
-
-	switch mode {
-	case "encode":
-		output = url.QueryEscape(request.Input)
-	case "decode":
-		decoded, err := url.QueryUnescape(request.Input)
-		if err != nil {
-			return &models.ToolResponse{
-				Error: fmt.Sprintf("Invalid URL encoding: %v", err),
-			}, nil
-		}
-		output = decoded
-	case "encode-component":
-		output = url.PathEscape(request.Input)
-	case "decode-component":
-		decoded, err := url.PathUnescape(request.Input)
-		if err != nil {
-			return &models.ToolResponse{
-				Error: fmt.Sprintf("Invalid URL path encoding: %v", err),
-			}, nil
+	if toolErr != nil {
+		toolErr.ToolID = toolID
+		if errors.Is(toolErr, ErrUnsupportedMode) {
+			if mode, ok := tool.SettingsSchema().Properties["mode"]; ok {
+				toolErr.Details["allowed"] = mode.Enum
+			}
 		}
-		output = decoded
-	default:
-		return &models.ToolResponse{
-			Error: fmt.Sprintf("Unsupported mode: %s", mode),
-		}, nil
 	}
-
-	return &models.ToolResponse{
-		Output: output,
-	}, nil
+	return response, err
 }
 
-// processHTML handles HTML entity encoding/decoding
-func (s *Service) processHTML(request models.ToolRequest) (*models.ToolResponse, error) {
-	mode := "encode"
-	if request.Settings != nil {
-		if m, ok := request.Settings["mode"].(string); ok {
-			mode = m
-		}
+// runTool validates the settings of request and runs tool with them, within
+// the limits of the service
+func (s *Service) runTool(ctx context.Context, tool Tool, request models.ToolRequest) (*models.ToolResponse, error) {
+	if len(request.Input) > s.limits.MaxInputBytes {
+		return nil, inputTooLarge(len(request.Input), s.limits.MaxInputBytes)
 	}
 
-	var output string
-
-	switch mode {
-	case "encode":
-		output = html.EscapeString(request.Input)
-	case "decode":
-		output = html.UnescapeString(request.Input)
-	default:
-		return &models.ToolResponse{
-			Error: fmt.Sprintf("Unsupported mode: %s", mode),
-		}, nil
+	settings, err := validateSettings(tool.SettingsSchema(), request.Settings)
+	var validationErr *SettingsValidationError
+	if errors.As(err, &validationErr) {
+		return nil, invalidSettings(validationErr)
 	}
-
-	return &models.ToolResponse{
-		Output: output,
-	}, nil
-}
-
-// processUnicode handles Unicode character encoding/decoding
-func (s *Service) processUnicode(request models.ToolRequest) (*models.ToolResponse, error) {
-	mode := "encode"
-	if request.Settings != nil {
-		if m, ok := request.Settings["mode"].(string); ok {
-			mode = m
-		}
+	if err != nil {
+		return nil, err
 	}
+	request.Settings = settings
 
-	var output string
+	ctx, cancel := context.WithTimeout(ctx, s.limits.Timeout)
+	defer cancel()
 
-	switch mode {
-	case "encode":
-		var parts []string
-		for _, r := range request.Input {
-			if r > 127 {
-				parts = append(parts, fmt.Sprintf("\\u%04x", r))
-			} else {
-				parts = append(parts, string(r))
-			}
-		}
-		output = strings.Join(parts, "")
-	case "decode":
-		// Simple unicode escape sequence decoder
-		output = request.Input
-		for i := 0; i < len(output)-5; i++ {
-			if output[i:i+2] == "\\u" {
-				if codePoint, err := strconv.ParseInt(output[i+2:i+6], 16, 32); err == nil {
-					output = output[:i] + string(rune(codePoint)) + output[i+6:]
-				}
+	type result struct {
+		response *models.ToolResponse
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		// Recovery middleware does not see panics on this goroutine
+		defer func() {
+			if p := recover(); p != nil {
+				done <- result{err: fmt.Errorf("tool panicked: %v", p)}
 			}
+		}()
+		response, err := tool.Process(request)
+		done <- result{response, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.response, r.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, toolTimeout(s.limits.Timeout)
 		}
-	case "info":
-		var info []string
-		for _, r := range request.Input {
-			info = append(info, fmt.Sprintf("'%c' (U+%04X)", r, r))
-		}
-		output = strings.Join(info, "\n")
-	default:
-		return &models.ToolResponse{
-			Error: fmt.Sprintf("Unsupported mode: %s", mode),
-		}, nil
+		return nil, ctx.Err()
 	}
-
-	return &models.ToolResponse{
-		Output: output,
-	}, nil
-} 
\ No newline at end of file
+}
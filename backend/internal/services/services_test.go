@@ -0,0 +1,26 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"web-tools-platform/backend/internal/database"
+)
+
+// newTestService returns a service backed by a fresh SQLite database with
+// the tool catalog synced
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+
+	db, err := database.Initialize(database.DriverSQLite, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("opening the database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.SyncTools(context.Background(), Catalog()); err != nil {
+		t.Fatalf("syncing tools: %v", err)
+	}
+
+	return NewService(db)
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"web-tools-platform/backend/internal/database"
+	"web-tools-platform/backend/internal/models"
+)
+
+// SettingsValidationError lists the settings that failed validation
+type SettingsValidationError struct {
+	Fields map[string]string
+}
+
+func (e *SettingsValidationError) Error() string {
+	keys := make([]string, 0, len(e.Fields))
+	for key := range e.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + ": " + e.Fields[key]
+	}
+	return strings.Join(parts, "; ")
+}
+
+// DefaultSettings returns the settings used when a user has not saved any
+func DefaultSettings() map[string]interface{} {
+	return map[string]interface{}{
+		"theme":       "light",
+		"language":    "en",
+		"fontSize":    "medium",
+		"autoSave":    true,
+		"toolHistory": true,
+	}
+}
+
+// UserSettingsSchema describes the settings accepted by ValidateSettings,
+// with their defaults. It mirrors the UserSettings interface in shared/types.
+func UserSettingsSchema() models.SettingsSchema {
+	defaults := DefaultSettings()
+	return models.SettingsSchema{
+		Type: "object",
+		Properties: map[string]models.SettingProperty{
+			"theme": {
+				Type:    "string",
+				Enum:    []interface{}{"light", "dark", "system"},
+				Default: defaults["theme"],
+			},
+			"language": {
+				Type:      "string",
+				Default:   defaults["language"],
+				MinLength: 1,
+				MaxLength: 35,
+			},
+			"fontSize": {
+				Type:    "string",
+				Enum:    []interface{}{"small", "medium", "large"},
+				Default: defaults["fontSize"],
+			},
+			"autoSave":    {Type: "boolean", Default: defaults["autoSave"]},
+			"toolHistory": {Type: "boolean", Default: defaults["toolHistory"]},
+		},
+	}
+}
+
+// ValidateSettings checks settings against UserSettingsSchema. Unknown keys
+// and values of the wrong type are rejected.
+func ValidateSettings(settings map[string]interface{}) error {
+	_, err := validateSettings(UserSettingsSchema(), settings)
+	return err
+}
+
+// GetSettings returns the defaults merged with the settings saved by userID
+func (s *Service) GetSettings(ctx context.Context, userID string) (map[string]interface{}, error) {
+	stored, err := s.storedSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeSettings(DefaultSettings(), stored), nil
+}
+
+// ReplaceSettings validates and saves a complete set of settings for userID.
+// Keys that are not given fall back to their defaults.
+func (s *Service) ReplaceSettings(ctx context.Context, userID string, settings map[string]interface{}) (map[string]interface{}, error) {
+	if err := ValidateSettings(settings); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.SaveUserSettings(ctx, userID, settings); err != nil {
+		return nil, err
+	}
+
+	return mergeSettings(DefaultSettings(), settings), nil
+}
+
+// PatchSettings validates settings and merges them into those already saved
+// for userID
+func (s *Service) PatchSettings(ctx context.Context, userID string, settings map[string]interface{}) (map[string]interface{}, error) {
+	if err := ValidateSettings(settings); err != nil {
+		return nil, err
+	}
+
+	stored, err := s.storedSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stored = mergeSettings(stored, settings)
+	if err := s.db.SaveUserSettings(ctx, userID, stored); err != nil {
+		return nil, err
+	}
+
+	return mergeSettings(DefaultSettings(), stored), nil
+}
+
+// storedSettings returns the settings saved by userID, or an empty map
+func (s *Service) storedSettings(ctx context.Context, userID string) (map[string]interface{}, error) {
+	if userID == "" {
+		return map[string]interface{}{}, nil
+	}
+
+	userSettings, err := s.db.GetUserSettings(ctx, userID)
+	if errors.Is(err, database.ErrNotFound) {
+		return map[string]interface{}{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return userSettings.Settings, nil
+}
+
+// mergeSettings returns a copy of base overlaid with overrides
+func mergeSettings(base, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overrides))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		merged[key] = value
+	}
+	return merged
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+func TestValidateSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		fields   map[string]string
+	}{
+		{
+			name:     "valid",
+			settings: map[string]interface{}{"theme": "dark", "language": "de", "autoSave": false},
+		},
+		{
+			name: "invalid",
+			settings: map[string]interface{}{
+				"theme":       "blue",
+				"language":    "",
+				"fontSize":    12.0,
+				"toolHistory": "yes",
+				"colour":      "red",
+			},
+			fields: map[string]string{
+				"theme":       "must be one of light, dark, system",
+				"language":    "must not be empty",
+				"fontSize":    "must be a string",
+				"toolHistory": "must be a boolean",
+				"colour":      "unknown setting",
+			},
+		},
+		{
+			name:     "too long",
+			settings: map[string]interface{}{"language": "a-very-long-language-tag-that-is-invalid"},
+			fields:   map[string]string{"language": "must be at most 35 characters"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSettings(tt.settings)
+			if tt.fields == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var validationErr *SettingsValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("got %v, want a *SettingsValidationError", err)
+			}
+			if !reflect.DeepEqual(validationErr.Fields, tt.fields) {
+				t.Errorf("fields = %v, want %v", validationErr.Fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestValidateToolSettings(t *testing.T) {
+	schema := jsonTool{}.SettingsSchema()
+
+	settings, err := validateSettings(schema, map[string]interface{}{"mode": "minify"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if settings["mode"] != "minify" {
+		t.Errorf("mode = %v, want minify", settings["mode"])
+	}
+	if settings["indent"] != 2 {
+		t.Errorf("indent = %v, want the default 2", settings["indent"])
+	}
+
+	_, err = validateSettings(schema, map[string]interface{}{
+		"mode":   "shout",
+		"indent": 2.5,
+		"colour": "red",
+	})
+	var validationErr *SettingsValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("got %v, want a *SettingsValidationError", err)
+	}
+	want := map[string]string{
+		"mode":   "must be one of format, prettify, minify, validate",
+		"indent": "must be an integer",
+		"colour": "unknown setting",
+	}
+	if !reflect.DeepEqual(validationErr.Fields, want) {
+		t.Errorf("fields = %v, want %v", validationErr.Fields, want)
+	}
+
+	_, err = validateSettings(schema, map[string]interface{}{"indent": 9.0})
+	if !errors.As(err, &validationErr) || validationErr.Fields["indent"] != "must be at most 8" {
+		t.Errorf("got %v, want indent to be rejected", err)
+	}
+}
+
+func TestProcessToolRejectsInvalidSettings(t *testing.T) {
+	s := &Service{registry: defaultRegistry, limits: DefaultLimits}
+
+	_, err := s.processTool(context.Background(), "json", models.ToolRequest{
+		Input:    "{}",
+		Settings: map[string]interface{}{"indent": "wide"},
+	})
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Kind != ErrInvalidSettings {
+		t.Fatalf("got %v, want an ErrInvalidSettings tool error", err)
+	}
+	if fields, _ := toolErr.Details["fields"].(map[string]string); fields["indent"] != "must be a number" {
+		t.Errorf("details = %v, want the indent field reported", toolErr.Details)
+	}
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+func init() {
+	Register(base64Tool{})
+}
+
+// base64Tool handles base64 encoding/decoding
+type base64Tool struct{}
+
+func (base64Tool) ID() string { return "base64" }
+
+func (base64Tool) Metadata() models.Tool {
+	return models.Tool{
+		ID:          "base64",
+		Name:        "Base64 Encoder/Decoder",
+		Description: "Encode and decode Base64 strings",
+		Category:    "encoding",
+		Icon:        "code",
+		Features:    []string{"input-validation", "output-formatting"},
+	}
+}
+
+func (base64Tool) SettingsSchema() models.SettingsSchema {
+	return modeSchema("encode", "encode", "decode", "url-encode", "url-decode")
+}
+
+func (base64Tool) Process(request models.ToolRequest) (*models.ToolResponse, error) {
+	mode := stringSetting(request.Settings, "mode", "encode")
+
+	var output string
+
+	switch mode {
+	case "encode":
+		output = base64.StdEncoding.EncodeToString([]byte(request.Input))
+	case "decode":
+		decoded, decodeErr := base64.StdEncoding.DecodeString(request.Input)
+		if decodeErr != nil {
+			return nil, invalidInput("Invalid base64 string: %v", decodeErr)
+		}
+		output = string(decoded)
+	case "url-encode":
+		output = base64.URLEncoding.EncodeToString([]byte(request.Input))
+	case "url-decode":
+		decoded, decodeErr := base64.URLEncoding.DecodeString(request.Input)
+		if decodeErr != nil {
+			return nil, invalidInput("Invalid base64 URL string: %v", decodeErr)
+		}
+		output = string(decoded)
+	default:
+		return nil, unsupportedMode(mode)
+	}
+
+	return &models.ToolResponse{
+		Output: output,
+	}, nil
+}
+
+// decodeBase64Lenient decodes standard or URL-safe base64, with or without
+// padding
+func decodeBase64Lenient(input string) ([]byte, error) {
+	encoding := base64.StdEncoding
+	if strings.ContainsAny(input, "-_") {
+		encoding = base64.URLEncoding
+	}
+	if !strings.HasSuffix(input, "=") && len(input)%4 != 0 {
+		encoding = encoding.WithPadding(base64.NoPadding)
+	}
+
+	decoded, err := encoding.DecodeString(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 string: %v", err)
+	}
+	return decoded, nil
+}
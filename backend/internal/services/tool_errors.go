@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Kinds of ToolError, matched with errors.Is
+var (
+	ErrToolNotFound    = errors.New("tool not found")
+	ErrInvalidInput    = errors.New("invalid input")
+	ErrInvalidSettings = errors.New("invalid settings")
+	ErrUnsupportedMode = errors.New("unsupported mode")
+	ErrInputTooLarge   = errors.New("input too large")
+	ErrToolTimeout     = errors.New("tool timed out")
+)
+
+// ToolError is a failure to process a request caused by the request itself
+// or by the processing limits, rather than by the server. Message is meant
+// for the client, Details holds machine-readable context such as the
+// position of a syntax error.
+type ToolError struct {
+	Kind    error
+	ToolID  string
+	Message string
+	Details map[string]interface{}
+}
+
+func (e *ToolError) Error() string {
+	if e.ToolID == "" {
+		return e.Message
+	}
+	return e.ToolID + ": " + e.Message
+}
+
+func (e *ToolError) Unwrap() error {
+	return e.Kind
+}
+
+// invalidInput reports input a tool cannot process
+func invalidInput(format string, args ...interface{}) *ToolError {
+	return &ToolError{Kind: ErrInvalidInput, Message: fmt.Sprintf(format, args...)}
+}
+
+// unsupportedMode reports a mode setting the tool does not implement; the
+// allowed modes are added from the tool's settings schema
+func unsupportedMode(mode string) *ToolError {
+	return unsupportedOption("mode", "mode", mode)
+}
+
+// unsupportedOption reports a value of setting, described to the client as
+// name, that is not implemented
+func unsupportedOption(setting, name, value string) *ToolError {
+	return &ToolError{
+		Kind:    ErrUnsupportedMode,
+		Message: fmt.Sprintf("Unsupported %s: %s", name, value),
+		Details: map[string]interface{}{"setting": setting, "value": value},
+	}
+}
+
+func toolNotFound(toolID string) *ToolError {
+	return &ToolError{
+		Kind:    ErrToolNotFound,
+		ToolID:  toolID,
+		Message: fmt.Sprintf("Unknown tool: %s", toolID),
+	}
+}
+
+func inputTooLarge(size, limit int) *ToolError {
+	return &ToolError{
+		Kind:    ErrInputTooLarge,
+		Message: fmt.Sprintf("Input of %d bytes exceeds the limit of %d bytes", size, limit),
+		Details: map[string]interface{}{"sizeBytes": size, "limitBytes": limit},
+	}
+}
+
+func toolTimeout(timeout time.Duration) *ToolError {
+	return &ToolError{
+		Kind:    ErrToolTimeout,
+		Message: fmt.Sprintf("Processing took longer than %s", timeout),
+		Details: map[string]interface{}{"timeoutMs": timeout.Milliseconds()},
+	}
+}
+
+// pipelineTimeout reports a pipeline step that was stopped, or never
+// started, because the pipeline as a whole ran out of time
+func pipelineTimeout(timeout time.Duration) *ToolError {
+	return &ToolError{
+		Kind:    ErrToolTimeout,
+		Message: fmt.Sprintf("Pipeline took longer than %s", timeout),
+		Details: map[string]interface{}{"pipelineTimeoutMs": timeout.Milliseconds()},
+	}
+}
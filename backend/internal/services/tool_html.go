@@ -0,0 +1,50 @@
+package services
+
+import (
+	"html"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+func init() {
+	Register(htmlTool{})
+}
+
+// htmlTool handles HTML entity encoding/decoding
+type htmlTool struct{}
+
+func (htmlTool) ID() string { return "html" }
+
+func (htmlTool) Metadata() models.Tool {
+	return models.Tool{
+		ID:          "html",
+		Name:        "HTML Encoder/Decoder",
+		Description: "Encode and decode HTML entities",
+		Category:    "encoding",
+		Icon:        "code2",
+		Features:    []string{"input-validation", "output-formatting"},
+	}
+}
+
+func (htmlTool) SettingsSchema() models.SettingsSchema {
+	return modeSchema("encode", "encode", "decode")
+}
+
+func (htmlTool) Process(request models.ToolRequest) (*models.ToolResponse, error) {
+	mode := stringSetting(request.Settings, "mode", "encode")
+
+	var output string
+
+	switch mode {
+	case "encode":
+		output = html.EscapeString(request.Input)
+	case "decode":
+		output = html.UnescapeString(request.Input)
+	default:
+		return nil, unsupportedMode(mode)
+	}
+
+	return &models.ToolResponse{
+		Output: output,
+	}, nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+func init() {
+	Register(jsonTool{})
+}
+
+// jsonTool handles JSON formatting and validation
+type jsonTool struct{}
+
+func (jsonTool) ID() string { return "json" }
+
+func (jsonTool) Metadata() models.Tool {
+	return models.Tool{
+		ID:          "json",
+		Name:        "JSON Formatter/Validator",
+		Description: "Format and validate JSON data",
+		Category:    "formatting",
+		Icon:        "braces",
+		Features:    []string{"input-validation", "output-formatting", "settings"},
+	}
+}
+
+func (jsonTool) SettingsSchema() models.SettingsSchema {
+	schema := modeSchema("format", "format", "prettify", "minify", "validate")
+	schema.Properties["indent"] = models.SettingProperty{
+		Type:        "integer",
+		Description: "Spaces per indentation level when formatting",
+		Default:     2,
+		Minimum:     bound(0),
+		Maximum:     bound(8),
+	}
+	return schema
+}
+
+func (jsonTool) Process(request models.ToolRequest) (*models.ToolResponse, error) {
+	mode := stringSetting(request.Settings, "mode", "format")
+
+	var output string
+	var jsonData interface{}
+
+	// First, validate the JSON
+	if err := json.Unmarshal([]byte(request.Input), &jsonData); err != nil {
+		toolErr := invalidInput("Invalid JSON: %v", err)
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			toolErr.Details = map[string]interface{}{"offset": syntaxErr.Offset}
+		}
+		return nil, toolErr
+	}
+
+	switch mode {
+	case "format", "prettify":
+		indent := strings.Repeat(" ", intSetting(request.Settings, "indent", 2))
+		formatted, err := json.MarshalIndent(jsonData, "", indent)
+		if err != nil {
+			return nil, fmt.Errorf("formatting JSON: %w", err)
+		}
+		output = string(formatted)
+	case "minify":
+		minified, err := json.Marshal(jsonData)
+		if err != nil {
+			return nil, fmt.Errorf("minifying JSON: %w", err)
+		}
+		output = string(minified)
+	case "validate":
+		output = "Valid JSON"
+	default:
+		return nil, unsupportedMode(mode)
+	}
+
+	return &models.ToolResponse{
+		Output: output,
+	}, nil
+}
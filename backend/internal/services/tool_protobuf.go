@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+func init() {
+	Register(protobufTool{})
+}
+
+// protobufTool formats protobuf text format / DebugString() dumps and decodes
+// binary messages without a schema
+type protobufTool struct{}
+
+func (protobufTool) ID() string { return "protobuf" }
+
+func (protobufTool) Metadata() models.Tool {
+	return models.Tool{
+		ID:          "protobuf",
+		Name:        "Protobuf Debug String Formatter",
+		Description: "Format protobuf debug strings and decode binary messages",
+		Category:    "protocol",
+		Icon:        "settings",
+		Features:    []string{"input-validation", "output-formatting"},
+	}
+}
+
+func (protobufTool) SettingsSchema() models.SettingsSchema {
+	schema := modeSchema("format", "format", "minify", "validate", "decode-raw")
+	schema.Properties["encoding"] = models.SettingProperty{
+		Type:        "string",
+		Description: "Encoding of the binary payload for decode-raw",
+		Enum:        []interface{}{"auto", "base64", "hex"},
+		Default:     "auto",
+	}
+	return schema
+}
+
+func (protobufTool) Process(request models.ToolRequest) (*models.ToolResponse, error) {
+	mode := stringSetting(request.Settings, "mode", "format")
+
+	switch mode {
+	case "format", "minify", "validate":
+	case "decode-raw":
+		return processProtobufWire(request)
+	default:
+		return nil, unsupportedMode(mode)
+	}
+
+	fields, err := parseTextFormat(request.Input)
+	if err != nil {
+		toolErr := invalidInput("Invalid protobuf text format: %v", err)
+		var syntaxErr *textSyntaxError
+		if errors.As(err, &syntaxErr) {
+			toolErr.Details = map[string]interface{}{
+				"line":   syntaxErr.Line,
+				"column": syntaxErr.Column,
+			}
+		}
+		return nil, toolErr
+	}
+
+	var output string
+
+	switch mode {
+	case "format":
+		output = formatTextFields(fields, "  ")
+	case "minify":
+		output = formatTextFields(fields, "")
+	case "validate":
+		output = "Valid protobuf text format"
+	}
+
+	return &models.ToolResponse{
+		Output: output,
+		Metadata: map[string]interface{}{
+			"fields": countTextFields(fields),
+		},
+	}, nil
+}
+
+// processProtobufWire decodes a base64 or hex encoded binary message without a
+// schema
+func processProtobufWire(request models.ToolRequest) (*models.ToolResponse, error) {
+	encoding := stringSetting(request.Settings, "encoding", "auto")
+
+	data, err := decodeWireInput(request.Input, encoding)
+	if err != nil {
+		return nil, invalidInput("Invalid binary input: %v", err)
+	}
+
+	fields, err := decodeWireMessage(data, 0)
+	if err != nil {
+		return nil, invalidInput("Invalid protobuf wire format: %v", err)
+	}
+
+	return &models.ToolResponse{
+		Output: formatWireFields(fields, 0),
+		Metadata: map[string]interface{}{
+			"fields": countWireFields(fields),
+			"bytes":  len(data),
+		},
+	}, nil
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+// validateSettings checks settings against schema and reports every invalid setting in a *SettingsValidationError
+func validateSettings(schema models.SettingsSchema, settings map[string]interface{}) (map[string]interface{}, error) {
+	fields := map[string]string{}
+	for key := range settings {
+		if _, ok := schema.Properties[key]; !ok {
+			fields[key] = "unknown setting"
+		}
+	}
+
+	validated := make(map[string]interface{}, len(schema.Properties))
+	for key, property := range schema.Properties {
+		value, ok := settings[key]
+		if !ok {
+			if property.Default != nil {
+				validated[key] = property.Default
+			}
+			continue
+		}
+		if problem := checkSetting(property, value); problem != "" {
+			fields[key] = problem
+			continue
+		}
+		validated[key] = value
+	}
+
+	if len(fields) > 0 {
+		return nil, &SettingsValidationError{Fields: fields}
+	}
+	return validated, nil
+}
+
+// checkSetting describes what is wrong with value, or returns ""
+func checkSetting(property models.SettingProperty, value interface{}) string {
+	switch property.Type {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return "must be a string"
+		}
+		if s == "" && property.MinLength > 0 {
+			return "must not be empty"
+		}
+		if len(s) < property.MinLength {
+			return fmt.Sprintf("must be at least %d characters", property.MinLength)
+		}
+		if property.MaxLength > 0 && len(s) > property.MaxLength {
+			return fmt.Sprintf("must be at most %d characters", property.MaxLength)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return "must be a boolean"
+		}
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			return "must be a number"
+		}
+		if property.Type == "integer" && n != math.Trunc(n) {
+			return "must be an integer"
+		}
+		if property.Minimum != nil && n < *property.Minimum {
+			return fmt.Sprintf("must be at least %g", *property.Minimum)
+		}
+		if property.Maximum != nil && n > *property.Maximum {
+			return fmt.Sprintf("must be at most %g", *property.Maximum)
+		}
+	}
+
+	if len(property.Enum) > 0 {
+		allowed := make([]string, len(property.Enum))
+		for i, option := range property.Enum {
+			if option == value {
+				return ""
+			}
+			allowed[i] = fmt.Sprint(option)
+		}
+		return "must be one of " + strings.Join(allowed, ", ")
+	}
+	return ""
+}
+
+// invalidSettings reports tool settings rejected by validateSettings
+func invalidSettings(validationErr *SettingsValidationError) *ToolError {
+	return &ToolError{
+		Kind:    ErrInvalidSettings,
+		Message: "Invalid settings: " + validationErr.Error(),
+		Details: map[string]interface{}{"fields": validationErr.Fields},
+	}
+}
+
+// intSetting returns the integer setting stored under key, or def if unset.
+// Numbers decoded from JSON are float64, defaults from schemas int.
+func intSetting(settings map[string]interface{}, key string, def int) int {
+	switch v := settings[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return def
+}
+
+// bound returns a pointer to n, for SettingProperty bounds
+func bound(n float64) *float64 {
+	return &n
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+func init() {
+	Register(unicodeTool{})
+}
+
+// unicodeTool handles Unicode character encoding/decoding
+type unicodeTool struct{}
+
+func (unicodeTool) ID() string { return "unicode" }
+
+func (unicodeTool) Metadata() models.Tool {
+	return models.Tool{
+		ID:          "unicode",
+		Name:        "Unicode Encoder/Decoder",
+		Description: "Encode and decode Unicode characters",
+		Category:    "encoding",
+		Icon:        "globe",
+		Features:    []string{"input-validation", "output-formatting"},
+	}
+}
+
+func (unicodeTool) SettingsSchema() models.SettingsSchema {
+	return modeSchema("encode", "encode", "decode", "info")
+}
+
+func (unicodeTool) Process(request models.ToolRequest) (*models.ToolResponse, error) {
+	mode := stringSetting(request.Settings, "mode", "encode")
+
+	var output string
+
+	switch mode {
+	case "encode":
+		var parts []string
+		for _, r := range request.Input {
+			if r > 127 {
+				parts = append(parts, fmt.Sprintf("\\u%04x", r))
+			} else {
+				parts = append(parts, string(r))
+			}
+		}
+		output = strings.Join(parts, "")
+	case "decode":
+		// Simple unicode escape sequence decoder
+		output = request.Input
+		for i := 0; i < len(output)-5; i++ {
+			if output[i:i+2] == "\\u" {
+				if codePoint, err := strconv.ParseInt(output[i+2:i+6], 16, 32); err == nil {
+					output = output[:i] + string(rune(codePoint)) + output[i+6:]
+				}
+			}
+		}
+	case "info":
+		var info []string
+		for _, r := range request.Input {
+			info = append(info, fmt.Sprintf("'%c' (U+%04X)", r, r))
+		}
+		output = strings.Join(info, "\n")
+	default:
+		return nil, unsupportedMode(mode)
+	}
+
+	return &models.ToolResponse{
+		Output: output,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"net/url"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+func init() {
+	Register(urlTool{})
+}
+
+// urlTool handles URL encoding/decoding
+type urlTool struct{}
+
+func (urlTool) ID() string { return "url" }
+
+func (urlTool) Metadata() models.Tool {
+	return models.Tool{
+		ID:          "url",
+		Name:        "URL Encoder/Decoder",
+		Description: "Encode and decode URL parameters",
+		Category:    "encoding",
+		Icon:        "link",
+		Features:    []string{"input-validation", "output-formatting"},
+	}
+}
+
+func (urlTool) SettingsSchema() models.SettingsSchema {
+	return modeSchema("encode", "encode", "decode", "encode-component", "decode-component")
+}
+
+func (urlTool) Process(request models.ToolRequest) (*models.ToolResponse, error) {
+	mode := stringSetting(request.Settings, "mode", "encode")
+
+	var output string
+
+	switch mode {
+	case "encode":
+		output = url.QueryEscape(request.Input)
+	case "decode":
+		decoded, err := url.QueryUnescape(request.Input)
+		if err != nil {
+			return nil, invalidInput("Invalid URL encoding: %v", err)
+		}
+		output = decoded
+	case "encode-component":
+		output = url.PathEscape(request.Input)
+	case "decode-component":
+		decoded, err := url.PathUnescape(request.Input)
+		if err != nil {
+			return nil, invalidInput("Invalid URL path encoding: %v", err)
+		}
+		output = decoded
+	default:
+		return nil, unsupportedMode(mode)
+	}
+
+	return &models.ToolResponse{
+		Output: output,
+	}, nil
+}
@@ -0,0 +1,43 @@
+// Package version holds the build information reported by the health
+// endpoints. The values are injected at build time:
+//
+//	go build -ldflags "-X web-tools-platform/backend/internal/version.Version=v1.2.0 \
+//	  -X web-tools-platform/backend/internal/version.Commit=$(git rev-parse HEAD) \
+//	  -X web-tools-platform/backend/internal/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/server
+//
+// Commit falls back to the revision Go embeds when building from a git
+// checkout.
+package version
+
+import "runtime/debug"
+
+// unknown is reported for values that were neither injected nor embedded
+const unknown = "unknown"
+
+var (
+	// Version is the release version, "dev" for local builds
+	Version = "dev"
+
+	// Commit is the git commit the binary was built from
+	Commit = ""
+
+	// BuildTime is when the binary was built, in RFC 3339 format
+	BuildTime = ""
+)
+
+func init() {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" && Commit == "" {
+				Commit = setting.Value
+			}
+		}
+	}
+
+	if Commit == "" {
+		Commit = unknown
+	}
+	if BuildTime == "" {
+		BuildTime = unknown
+	}
+}
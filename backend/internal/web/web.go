@@ -0,0 +1,252 @@
+// Package web serves the frontend build embedded in the binary. The Vite
+// output is copied into dist by scripts/embed-frontend.sh before building;
+// without it the binary serves the API only.
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"web-tools-platform/backend/internal/models"
+)
+
+//go:embed all:dist
+var dist embed.FS
+
+// ErrNotEmbedded is returned by New when the binary was built without the
+// frontend
+var ErrNotEmbedded = errors.New("frontend build not embedded, run scripts/embed-frontend.sh before building")
+
+const (
+	indexFile = "index.html"
+
+	// assetsPrefix holds Vite's content-hashed files, which never change
+	// under the same name
+	assetsPrefix = "/assets/"
+
+	immutableCache  = "public, max-age=31536000, immutable"
+	revalidateCache = "no-cache"
+)
+
+// encodings lists the precompressed variants in order of preference, by
+// Content-Encoding and file suffix
+var encodings = []struct {
+	name   string
+	suffix string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// contentTypes covers the files Vite emits, independent of the host's
+// mime.types
+var contentTypes = map[string]string{
+	".html":  "text/html; charset=utf-8",
+	".js":    "text/javascript; charset=utf-8",
+	".mjs":   "text/javascript; charset=utf-8",
+	".css":   "text/css; charset=utf-8",
+	".json":  "application/json",
+	".map":   "application/json",
+	".svg":   "image/svg+xml",
+	".png":   "image/png",
+	".jpg":   "image/jpeg",
+	".ico":   "image/x-icon",
+	".webp":  "image/webp",
+	".woff":  "font/woff",
+	".woff2": "font/woff2",
+	".txt":   "text/plain; charset=utf-8",
+	".wasm":  "application/wasm",
+}
+
+// file is an embedded file and its precompressed variants
+type file struct {
+	content     []byte
+	contentType string
+	etag        string
+	variants    map[string][]byte
+}
+
+// Frontend serves the files of a frontend build
+type Frontend struct {
+	files map[string]*file
+}
+
+// Dist returns the embedded frontend build
+func Dist() fs.FS {
+	sub, err := fs.Sub(dist, "dist")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// New loads every file of the build in files, which must contain
+// index.html at its root
+func New(files fs.FS) (*Frontend, error) {
+	if _, err := fs.Stat(files, indexFile); err != nil {
+		return nil, ErrNotEmbedded
+	}
+
+	frontend := &Frontend{files: map[string]*file{}}
+	err := fs.WalkDir(files, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			return err
+		}
+		content, err := fs.ReadFile(files, name)
+		if err != nil {
+			return err
+		}
+		frontend.add(name, content)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Attach variants once every file is loaded; they are only served in
+	// place of their original
+	for name, f := range frontend.files {
+		for _, encoding := range encodings {
+			if variant, ok := frontend.files[name+encoding.suffix]; ok {
+				f.variants[encoding.name] = variant.content
+			}
+		}
+	}
+
+	return frontend, nil
+}
+
+func (f *Frontend) add(name string, content []byte) {
+	sum := sha256.Sum256(content)
+	f.files["/"+name] = &file{
+		content:     content,
+		contentType: contentType(name, content),
+		etag:        `"` + hex.EncodeToString(sum[:8]) + `"`,
+		variants:    map[string][]byte{},
+	}
+}
+
+// Files returns the number of files served, excluding compressed variants
+func (f *Frontend) Files() int {
+	count := 0
+	for name := range f.files {
+		if !isVariant(name) {
+			count++
+		}
+	}
+	return count
+}
+
+// Serve handles requests that matched no route. Build files are served as
+// is; other GET requests outside /api and /assets get index.html so that
+// client-side routes such as /tools/json survive a reload.
+func (f *Frontend) Serve(c *gin.Context) {
+	name := path.Clean("/" + c.Request.URL.Path)
+	if name == "/" {
+		name = "/" + indexFile
+	}
+
+	method := c.Request.Method
+	if (method != http.MethodGet && method != http.MethodHead) || isAPI(name) {
+		NotFound(c)
+		return
+	}
+
+	requested, ok := f.files[name]
+	if !ok || isVariant(name) {
+		if strings.HasPrefix(name, assetsPrefix) || path.Ext(name) != "" {
+			NotFound(c)
+			return
+		}
+		name, requested = "/"+indexFile, f.files["/"+indexFile]
+	}
+
+	cacheControl := revalidateCache
+	if strings.HasPrefix(name, assetsPrefix) {
+		cacheControl = immutableCache
+	}
+
+	header := c.Writer.Header()
+	header.Set("Cache-Control", cacheControl)
+	header.Set("Content-Type", requested.contentType)
+	header.Set("X-Content-Type-Options", "nosniff")
+
+	content, etag := requested.content, requested.etag
+	if len(requested.variants) > 0 {
+		header.Add("Vary", "Accept-Encoding")
+		accepted := c.GetHeader("Accept-Encoding")
+		for _, encoding := range encodings {
+			variant, ok := requested.variants[encoding.name]
+			if ok && acceptsEncoding(accepted, encoding.name) {
+				header.Set("Content-Encoding", encoding.name)
+				content = variant
+				etag = strings.TrimSuffix(etag, `"`) + "-" + encoding.name + `"`
+				break
+			}
+		}
+	}
+	header.Set("ETag", etag)
+
+	http.ServeContent(c.Writer, c.Request, name, time.Time{}, bytes.NewReader(content))
+}
+
+// NotFound responds to requests that matched no route or file
+func NotFound(c *gin.Context) {
+	c.JSON(http.StatusNotFound, models.ErrorResponse{
+		Error: "Route not found",
+		Code:  "NOT_FOUND",
+	})
+}
+
+func isAPI(name string) bool {
+	return name == "/api" || strings.HasPrefix(name, "/api/")
+}
+
+func isVariant(name string) bool {
+	for _, encoding := range encodings {
+		if strings.HasSuffix(name, encoding.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsEncoding reports whether an Accept-Encoding header allows
+// encoding, honouring q=0 exclusions
+func acceptsEncoding(header, encoding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(name), encoding) {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			weight, err := strconv.ParseFloat(q, 64)
+			return err == nil && weight > 0
+		}
+		return true
+	}
+	return false
+}
+
+func contentType(name string, content []byte) string {
+	ext := path.Ext(name)
+	if contentType, ok := contentTypes[ext]; ok {
+		return contentType
+	}
+	if contentType := mime.TypeByExtension(ext); contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(content)
+}
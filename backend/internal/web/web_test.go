@@ -0,0 +1,127 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter serves a small build with a precompressed bundle
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	frontend, err := New(fstest.MapFS{
+		"index.html":            {Data: []byte("<!doctype html><title>app</title>")},
+		"assets/app-1a2b.js":    {Data: []byte("console.log('app')")},
+		"assets/app-1a2b.js.br": {Data: []byte("brotli")},
+		"assets/app-1a2b.js.gz": {Data: []byte("gzip")},
+		"robots.txt":            {Data: []byte("User-agent: *")},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if got := frontend.Files(); got != 3 {
+		t.Errorf("Files() = %d, want 3", got)
+	}
+
+	router := gin.New()
+	router.NoRoute(frontend.Serve)
+	return router
+}
+
+func TestServe(t *testing.T) {
+	router := newTestRouter(t)
+
+	const index = "<!doctype html><title>app</title>"
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		acceptEncoding string
+		status         int
+		body           string
+		encoding       string
+		cacheControl   string
+	}{
+		{"root", http.MethodGet, "/", "", http.StatusOK, index, "", revalidateCache},
+		{"client-side route", http.MethodGet, "/tools/json", "", http.StatusOK, index, "", revalidateCache},
+		{"head client-side route", http.MethodHead, "/tools/json", "", http.StatusOK, "", "", revalidateCache},
+		{"file", http.MethodGet, "/robots.txt", "", http.StatusOK, "User-agent: *", "", revalidateCache},
+		{"asset", http.MethodGet, "/assets/app-1a2b.js", "", http.StatusOK, "console.log('app')", "", immutableCache},
+		{"brotli preferred", http.MethodGet, "/assets/app-1a2b.js", "gzip, br", http.StatusOK, "brotli", "br", immutableCache},
+		{"gzip", http.MethodGet, "/assets/app-1a2b.js", "gzip", http.StatusOK, "gzip", "gzip", immutableCache},
+		{"brotli refused", http.MethodGet, "/assets/app-1a2b.js", "br;q=0, gzip", http.StatusOK, "gzip", "gzip", immutableCache},
+		{"all refused", http.MethodGet, "/assets/app-1a2b.js", "br;q=0, gzip;q=0", http.StatusOK, "console.log('app')", "", immutableCache},
+		{"variant requested directly", http.MethodGet, "/assets/app-1a2b.js.br", "", http.StatusNotFound, "", "", ""},
+		{"missing asset", http.MethodGet, "/assets/app-ffff.js", "", http.StatusNotFound, "", "", ""},
+		{"missing file", http.MethodGet, "/favicon.ico", "", http.StatusNotFound, "", "", ""},
+		{"api route", http.MethodGet, "/api/v1/unknown", "", http.StatusNotFound, "", "", ""},
+		{"post", http.MethodPost, "/tools/json", "", http.StatusNotFound, "", "", ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.status)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		if tt.method == http.MethodGet && w.Body.String() != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.name, w.Body, tt.body)
+		}
+		if got := w.Header().Get("Content-Encoding"); got != tt.encoding {
+			t.Errorf("%s: Content-Encoding = %q, want %q", tt.name, got, tt.encoding)
+		}
+		if got := w.Header().Get("Cache-Control"); got != tt.cacheControl {
+			t.Errorf("%s: Cache-Control = %q, want %q", tt.name, got, tt.cacheControl)
+		}
+	}
+}
+
+func TestServeRevalidates(t *testing.T) {
+	router := newTestRouter(t)
+
+	etags := map[string]bool{}
+	for _, acceptEncoding := range []string{"", "gzip", "br"} {
+		req := httptest.NewRequest(http.MethodGet, "/assets/app-1a2b.js", nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		etag := w.Header().Get("ETag")
+		if etag == "" || etags[etag] {
+			t.Errorf("Accept-Encoding %q: ETag = %q, want one per encoding", acceptEncoding, etag)
+		}
+		etags[etag] = true
+		if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+			t.Errorf("Accept-Encoding %q: Vary = %q, want Accept-Encoding", acceptEncoding, got)
+		}
+
+		req.Header.Set("If-None-Match", etag)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusNotModified {
+			t.Errorf("Accept-Encoding %q: revalidation status = %d, want %d", acceptEncoding, w.Code, http.StatusNotModified)
+		}
+	}
+}
+
+func TestNewRequiresIndex(t *testing.T) {
+	_, err := New(fstest.MapFS{"assets/app.js": {Data: []byte("app")}})
+	if !errors.Is(err, ErrNotEmbedded) {
+		t.Errorf("New without index.html = %v, want %v", err, ErrNotEmbedded)
+	}
+}
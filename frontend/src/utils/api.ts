@@ -1,7 +1,7 @@
 interface ApiError {
   error: string;
   code?: string;
-  details?: string;
+  details?: string | Record<string, any>;
 }
 
 interface ToolRequest {
@@ -22,12 +22,25 @@ interface Tool {
   category: string;
   icon: string;
   features: string[];
+  settingsSchema?: {
+    type: string;
+    properties?: Record<string, {
+      type: string;
+      description?: string;
+      enum?: Array<string | number | boolean>;
+      default?: string | number | boolean;
+      minimum?: number;
+      maximum?: number;
+      minLength?: number;
+      maxLength?: number;
+    }>;
+  };
 }
 
 class ApiClient {
   private baseUrl: string;
 
-  constructor(baseUrl: string = import.meta.env.VITE_API_URL || 'http://localhost:8080/api') {
+  constructor(baseUrl: string = import.meta.env.VITE_API_URL || 'http://localhost:8080/api/v1') {
     this.baseUrl = baseUrl;
   }
 
@@ -35,6 +48,7 @@ class ApiClient {
     const url = `${this.baseUrl}${endpoint}`;
     
     const response = await fetch(url, {
+      credentials: 'include',
       headers: {
         'Content-Type': 'application/json',
         ...options?.headers,
@@ -88,7 +102,7 @@ class ApiClient {
 
   // Health check
   async healthCheck(): Promise<{ status: string; timestamp: string; version: string }> {
-    const url = `${this.baseUrl.replace('/api', '')}/health`;
+    const url = `${this.baseUrl.replace(/\/api(\/v\d+)?$/, '')}/healthz`;
     const response = await fetch(url);
     if (!response.ok) {
       throw new Error(`Health check failed: ${response.statusText}`);
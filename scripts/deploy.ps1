@@ -32,27 +32,63 @@ if (-not (Test-Path "node_modules")) {
 Write-Host "🔨 Building frontend for production..." -ForegroundColor Yellow
 pnpm build
 
-# Copy frontend build to build directory
-Copy-Item -Recurse -Path "dist\*" -Destination "..\$BuildDir\"
 Write-Host "✅ Frontend build complete" -ForegroundColor Green
 
 Set-Location ..
 
+# Embed the frontend build into the backend binary (see scripts/embed-frontend.sh),
+# with precompressed variants of text files over 1KB
+Write-Host "📦 Embedding frontend..." -ForegroundColor Cyan
+$EmbedDir = "backend\internal\web\dist"
+Get-ChildItem $EmbedDir -Force | Where-Object { $_.Name -ne ".gitignore" } | Remove-Item -Recurse -Force
+Copy-Item -Recurse -Path "frontend\dist\*" -Destination $EmbedDir
+
+$HasBrotli = [System.Type]::GetType("System.IO.Compression.BrotliStream, System.IO.Compression") -ne $null
+if (-not $HasBrotli) {
+    Write-Host "⚠️  Brotli requires PowerShell 7, adding gzip variants only" -ForegroundColor Yellow
+}
+$Compressible = ".html", ".js", ".mjs", ".css", ".svg", ".json", ".map", ".txt", ".wasm"
+Get-ChildItem $EmbedDir -Recurse -File |
+    Where-Object { $Compressible -contains $_.Extension -and $_.Length -gt 1KB } |
+    ForEach-Object {
+        $Bytes = [System.IO.File]::ReadAllBytes($_.FullName)
+        $Variants = @{ ".gz" = { param($s) New-Object System.IO.Compression.GZipStream($s, [System.IO.Compression.CompressionLevel]::Optimal) } }
+        if ($HasBrotli) {
+            $Variants[".br"] = { param($s) New-Object System.IO.Compression.BrotliStream($s, [System.IO.Compression.CompressionLevel]::Optimal) }
+        }
+        foreach ($Suffix in $Variants.Keys) {
+            $Output = [System.IO.File]::Create($_.FullName + $Suffix)
+            $Stream = & $Variants[$Suffix] $Output
+            $Stream.Write($Bytes, 0, $Bytes.Length)
+            $Stream.Dispose()
+            $Output.Dispose()
+        }
+    }
+
 Write-Host "🔧 Building Backend..." -ForegroundColor Cyan
 Set-Location backend
 
 # Tidy Go modules
 go mod tidy
 
+# Version information reported by /healthz
+$VersionPkg = "web-tools-platform/backend/internal/version"
+$Version = if ($env:VERSION) { $env:VERSION } else { git describe --tags --always --dirty 2>$null }
+if (-not $Version) { $Version = "dev" }
+$Commit = git rev-parse HEAD 2>$null
+if (-not $Commit) { $Commit = "unknown" }
+$BuildTime = (Get-Date).ToUniversalTime().ToString("yyyy-MM-ddTHH:mm:ssZ")
+$LdFlags = "-X $VersionPkg.Version=$Version -X $VersionPkg.Commit=$Commit -X $VersionPkg.BuildTime=$BuildTime"
+
 # Build Go binary for Windows and Linux
 Write-Host "🔨 Building backend binary for Windows..." -ForegroundColor Yellow
 $env:CGO_ENABLED = "0"
 $env:GOOS = "windows"
-go build -o "..\$BuildDir\web-tools-server.exe" "cmd\server\main.go"
+go build -ldflags $LdFlags -o "..\$BuildDir\web-tools-server.exe" ".\cmd\server"
 
 Write-Host "🔨 Building backend binary for Linux..." -ForegroundColor Yellow
 $env:GOOS = "linux"
-go build -a -installsuffix cgo -o "..\$BuildDir\web-tools-server" "cmd\server\main.go"
+go build -a -installsuffix cgo -ldflags $LdFlags -o "..\$BuildDir\web-tools-server" ".\cmd\server"
 
 Write-Host "✅ Backend build complete" -ForegroundColor Green
 
@@ -69,6 +105,7 @@ if "%PORT%"=="" set PORT=8080
 if "%ENV%"=="" set ENV=production
 if "%LOG_LEVEL%"=="" set LOG_LEVEL=info
 
+web-tools-server.exe migrate up || exit /b 1
 echo Starting Web Tools Platform on port %PORT%
 web-tools-server.exe
 "@ | Out-File -FilePath "$BuildDir\start.bat" -Encoding ASCII
@@ -82,8 +119,9 @@ export PORT=${'$'}{PORT:-8080}
 export ENV=${'$'}{ENV:-production}
 export LOG_LEVEL=${'$'}{LOG_LEVEL:-info}
 
+./web-tools-server migrate up || exit 1
 echo "Starting Web Tools Platform on port ${'$'}PORT"
-./web-tools-server
+exec ./web-tools-server
 "@ | Out-File -FilePath "$BuildDir\start.sh" -Encoding UTF8
 
 # Create Dockerfile
@@ -108,7 +146,7 @@ USER webapp
 
 EXPOSE 8080
 
-CMD ["./web-tools-server"]
+CMD ["sh", "-c", "./web-tools-server migrate up && exec ./web-tools-server"]
 "@ | Out-File -FilePath "$BuildDir\Dockerfile" -Encoding UTF8
 
 # Create docker-compose.yml
@@ -125,7 +163,7 @@ services:
       - LOG_LEVEL=info
     restart: unless-stopped
     healthcheck:
-      test: ["CMD", "wget", "--quiet", "--tries=1", "--spider", "http://localhost:8080/health"]
+      test: ["CMD", "wget", "--quiet", "--tries=1", "--spider", "http://localhost:8080/readyz"]
       interval: 30s
       timeout: 10s
       retries: 3
@@ -186,17 +224,16 @@ cp .env.example .env
 
 ## Health Check
 
-Visit: http://localhost:8080/health
+Visit: http://localhost:8080/healthz
 
 ## API Documentation
 
-Visit: http://localhost:8080/api/tools
+Visit: http://localhost:8080/api/v1/tools
 "@ | Out-File -FilePath "$BuildDir\README.md" -Encoding UTF8
 
 Write-Host "📊 Build Summary" -ForegroundColor Green
 Write-Host "=================" -ForegroundColor Green
-Write-Host "✅ Frontend built and copied to $BuildDir/" -ForegroundColor Green
-Write-Host "✅ Backend binaries created:" -ForegroundColor Green
+Write-Host "✅ Backend binaries with embedded frontend created:" -ForegroundColor Green
 Write-Host "   - web-tools-server.exe (Windows)" -ForegroundColor White
 Write-Host "   - web-tools-server (Linux)" -ForegroundColor White
 Write-Host "✅ Deployment files created:" -ForegroundColor Green
@@ -208,7 +245,7 @@ Write-Host "   - .env.example (environment template)" -ForegroundColor White
 Write-Host "   - README.md (deployment instructions)" -ForegroundColor White
 
 # Get build sizes
-$FrontendFiles = Get-ChildItem "$BuildDir\*.html", "$BuildDir\*.js", "$BuildDir\*.css" -ErrorAction SilentlyContinue
+$FrontendFiles = Get-ChildItem "frontend\dist" -Recurse -File -ErrorAction SilentlyContinue
 $FrontendSize = if ($FrontendFiles) { 
     ($FrontendFiles | Measure-Object -Property Length -Sum).Sum / 1KB 
     "{0:F1}KB" -f $FrontendSize
@@ -32,21 +32,27 @@ fi
 echo "🔨 Building frontend for production..."
 pnpm build
 
-# Copy frontend build to build directory
-cp -r dist/* ../$BUILD_DIR/
 echo "✅ Frontend build complete"
 
 cd ..
 
+# Embed the frontend build into the backend binary
+./scripts/embed-frontend.sh
+
 echo "🔧 Building Backend..."
 cd backend
 
 # Tidy Go modules
 go mod tidy
 
-# Build Go binary
+# Build Go binary, stamping it with the version reported by /healthz
 echo "🔨 Building backend binary..."
-CGO_ENABLED=0 GOOS=linux go build -a -installsuffix cgo -o ../build/web-tools-server cmd/server/main.go
+VERSION_PKG="web-tools-platform/backend/internal/version"
+VERSION="${VERSION:-$(git describe --tags --always --dirty 2>/dev/null || echo dev)}"
+COMMIT="$(git rev-parse HEAD 2>/dev/null || echo unknown)"
+BUILD_TIME="$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+LDFLAGS="-X $VERSION_PKG.Version=$VERSION -X $VERSION_PKG.Commit=$COMMIT -X $VERSION_PKG.BuildTime=$BUILD_TIME"
+CGO_ENABLED=0 GOOS=linux go build -a -installsuffix cgo -ldflags "$LDFLAGS" -o ../build/web-tools-server ./cmd/server
 
 echo "✅ Backend build complete"
 
@@ -63,8 +69,9 @@ export PORT=${PORT:-8080}
 export ENV=${ENV:-production}
 export LOG_LEVEL=${LOG_LEVEL:-info}
 
+./web-tools-server migrate up || exit 1
 echo "Starting Web Tools Platform on port $PORT"
-./web-tools-server
+exec ./web-tools-server
 EOF
 
 chmod +x $BUILD_DIR/start.sh
@@ -91,7 +98,7 @@ USER webapp
 
 EXPOSE 8080
 
-CMD ["./web-tools-server"]
+CMD ["sh", "-c", "./web-tools-server migrate up && exec ./web-tools-server"]
 EOF
 
 # Create docker-compose.yml
@@ -108,7 +115,7 @@ services:
       - LOG_LEVEL=info
     restart: unless-stopped
     healthcheck:
-      test: ["CMD", "wget", "--quiet", "--tries=1", "--spider", "http://localhost:8080/health"]
+      test: ["CMD", "wget", "--quiet", "--tries=1", "--spider", "http://localhost:8080/readyz"]
       interval: 30s
       timeout: 10s
       retries: 3
@@ -164,17 +171,16 @@ cp .env.example .env
 
 ## Health Check
 
-Visit: http://localhost:8080/health
+Visit: http://localhost:8080/healthz
 
 ## API Documentation
 
-Visit: http://localhost:8080/api/tools
+Visit: http://localhost:8080/api/v1/tools
 EOF
 
 echo "📊 Build Summary"
 echo "=================="
-echo "✅ Frontend built and copied to $BUILD_DIR/"
-echo "✅ Backend binary: $BUILD_DIR/web-tools-server"
+echo "✅ Backend binary with embedded frontend: $BUILD_DIR/web-tools-server"
 echo "✅ Deployment files created:"
 echo "   - start.sh (startup script)"
 echo "   - Dockerfile (container deployment)"
@@ -183,7 +189,7 @@ echo "   - .env.example (environment template)"
 echo "   - README.md (deployment instructions)"
 
 # Get build size
-FRONTEND_SIZE=$(du -sh $BUILD_DIR/*.html $BUILD_DIR/*.js $BUILD_DIR/*.css 2>/dev/null | awk '{sum+=$1} END {print sum "K"}' || echo "N/A")
+FRONTEND_SIZE=$(du -sh frontend/dist | awk '{print $1}')
 BACKEND_SIZE=$(du -sh $BUILD_DIR/web-tools-server | awk '{print $1}')
 
 echo ""
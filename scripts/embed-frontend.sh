@@ -0,0 +1,46 @@
+#!/bin/bash
+
+# Web Tools Platform - Embed Frontend
+# Copies the frontend build into backend/internal/web/dist, where go:embed
+# picks it up, and adds gzip and brotli variants of compressible files so
+# the server never compresses at request time. Build the backend afterwards.
+#
+#   ./scripts/embed-frontend.sh [frontend-dist-dir]
+
+set -e  # Exit on error
+
+# Check if we're in the project root
+if [ ! -d "backend" ] || [ ! -d "frontend" ]; then
+    echo "❌ Error: Please run this script from the project root directory"
+    exit 1
+fi
+
+SOURCE=${1:-frontend/dist}
+TARGET=backend/internal/web/dist
+
+if [ ! -f "$SOURCE/index.html" ]; then
+    echo "❌ Error: $SOURCE/index.html not found, build the frontend first (cd frontend && pnpm build)"
+    exit 1
+fi
+
+echo "📦 Embedding $SOURCE into $TARGET..."
+
+# Keep the .gitignore that keeps the directory, and with it the embed, valid
+find "$TARGET" -mindepth 1 ! -name .gitignore -delete
+cp -R "$SOURCE"/. "$TARGET"/
+
+# Precompress text files; small files are not worth the extra request cost
+if ! command -v brotli >/dev/null; then
+    echo "⚠️  brotli not found, adding gzip variants only"
+fi
+find "$TARGET" -type f -size +1k \
+    \( -name '*.html' -o -name '*.js' -o -name '*.mjs' -o -name '*.css' -o -name '*.svg' \
+       -o -name '*.json' -o -name '*.map' -o -name '*.txt' -o -name '*.wasm' \) |
+while read -r file; do
+    gzip -9 -k -n -f "$file"
+    if command -v brotli >/dev/null; then
+        brotli -q 11 -k -f "$file"
+    fi
+done
+
+echo "✅ Embedded $(find "$TARGET" -type f ! -name '*.gz' ! -name '*.br' ! -name .gitignore | wc -l | tr -d ' ') files"
@@ -127,6 +127,7 @@ EOF
 PORT=8080
 ENV=development
 DB_PATH=./data/web-tools.db
+DB_AUTO_MIGRATE=true
 CORS_ORIGIN=http://localhost:5173
 LOG_LEVEL=info
 EOF
@@ -8,6 +8,24 @@ export interface Tool {
   component?: React.ComponentType<ToolProps>;
   apiEndpoint?: string;
   features: ToolFeature[];
+  settingsSchema?: SettingsSchema;
+}
+
+// Settings accepted by a tool, as returned by GET /api/v1/tools/:toolId
+export interface SettingsSchema {
+  type: 'object';
+  properties?: Record<string, SettingProperty>;
+}
+
+export interface SettingProperty {
+  type: 'string' | 'boolean' | 'integer' | 'number';
+  description?: string;
+  enum?: Array<string | number | boolean>;
+  default?: string | number | boolean;
+  minimum?: number;
+  maximum?: number;
+  minLength?: number;
+  maxLength?: number;
 }
 
 export interface ToolProps {